@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader คือชื่อ header ที่ใช้ส่ง API key
+// API key ส่งผ่าน Authorization: Bearer ได้ด้วย โดย TenantMiddleware จะไม่ตีความ key ที่ถูกต้องเป็น JWT
+const apiKeyHeader = "X-API-Key"
+
+// APIKeys คือชุดของ API key ที่อนุญาตให้เขียนข้อมูลได้
+type APIKeys struct {
+	keys     [][]byte
+	disabled bool
+}
+
+// LoadAPIKeys อ่าน API key จาก cfg.APIKeys และไฟล์ cfg.APIKeysFile (หนึ่ง key ต่อบรรทัด)
+// ถ้า cfg.AuthDisabled เป็น true จะไม่ตรวจสอบ API key เลย ใช้สำหรับพัฒนาบนเครื่องเท่านั้น
+func LoadAPIKeys(cfg Config) (APIKeys, error) {
+	if cfg.AuthDisabled {
+		return APIKeys{disabled: true}, nil
+	}
+
+	values := append([]string{}, cfg.APIKeys...)
+	if path := cfg.APIKeysFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return APIKeys{}, err
+		}
+		values = append(values, strings.Split(string(data), "\n")...)
+	}
+
+	var keys APIKeys
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			keys.keys = append(keys.keys, []byte(value))
+		}
+	}
+	return keys, nil
+}
+
+// valid ตรวจสอบ key กับทุก key ที่อนุญาตด้วยการเปรียบเทียบแบบ constant time
+// โดยไม่หยุดเมื่อพบ key ที่ตรงกัน เพื่อไม่ให้เวลาที่ใช้บอกได้ว่า key ใดตรง
+func (k APIKeys) valid(key string) bool {
+	match := 0
+	for _, allowed := range k.keys {
+		match |= subtle.ConstantTimeCompare(allowed, []byte(key))
+	}
+	return match == 1
+}
+
+// RequireAPIKey อนุญาตเฉพาะผู้เรียกที่ส่ง API key ที่ถูกต้อง หรือเข้าสู่ระบบด้วย JWT ที่มี role เป็น editor หรือ admin
+// ผู้ใช้ทั่วไปสมัครสมาชิกเองได้ JWT ที่ไม่มี role จึงใช้แทน API key ไม่ได้
+// ไม่มี key ได้ 401 และ key ไม่ถูกต้องหรือ role ไม่พอได้ 403 โดยตอบก่อนที่ handler จะอ่าน request body
+func RequireAPIKey(keys APIKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if keys.disabled || (claims != nil && (claims.Role == roleEditor || claims.Role == roleAdmin)) {
+			c.Next()
+			return
+		}
+
+		// ถ้ามี claims แสดงว่า Authorization เป็น JWT จึงอ่าน key จาก X-API-Key เท่านั้น
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" && claims == nil {
+			key = bearerToken(c)
+		}
+		switch {
+		case key == "" && claims != nil:
+			respondErr(c, http.StatusForbidden, codeForbidden, "API key or editor role required")
+		case key == "":
+			respondErr(c, http.StatusUnauthorized, codeUnauthorized, "API key required")
+		case !keys.valid(key):
+			respondErr(c, http.StatusForbidden, codeForbidden, "invalid API key")
+		default:
+			c.Next()
+			return
+		}
+		c.Abort()
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ผลที่คาดหวังของ route หนึ่งภายใต้สถานะการยืนยันตัวตนหนึ่ง
+// allowed หมายถึง request ผ่าน middleware ไปถึง handler ได้ ซึ่งตอบด้วย status อื่นที่ไม่ใช่ 401 หรือ 403
+const allowed = 0
+
+func TestWriteRoutesAuthMatrix(t *testing.T) {
+	server := newTestServer(t, nil)
+	w := server.do(http.MethodPost, "/recipes", `{"name":"Seed","description":"d","equipment":[]}`, apiKeyHeader, testAPIKey)
+	expectStatus(t, w, http.StatusCreated)
+
+	states := []struct {
+		name    string
+		headers []string
+	}{
+		{"anonymous", nil},
+		{"invalid key", []string{apiKeyHeader, "wrong"}},
+		{"valid key", []string{apiKeyHeader, testAPIKey}},
+		{"valid bearer key", []string{"Authorization", "Bearer " + testAPIKey}},
+		{"invalid bearer", []string{"Authorization", "Bearer wrong"}},
+		{"user JWT", []string{"Authorization", "Bearer " + testToken(t, "user")}},
+		{"user JWT with key", []string{"Authorization", "Bearer " + testToken(t, "user"), apiKeyHeader, testAPIKey}},
+		{"editor JWT", []string{"Authorization", "Bearer " + testToken(t, roleEditor)}},
+		{"admin JWT", []string{"Authorization", "Bearer " + testToken(t, roleAdmin)}},
+	}
+
+	// ลำดับของ want ตรงกับลำดับของ states
+	routes := []struct {
+		method, path, body string
+		want               []int
+	}{
+		{http.MethodPost, "/recipes", `{"name":"New","description":"d","equipment":[]}`,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodPost, "/recipes/batch", `[{"name":"Batch","description":"d","equipment":[]}]`,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodPut, "/recipes/1", `{"name":"Seed","description":"d2","equipment":[]}`,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodPatch, "/recipes/1", `{"description":"d3"}`,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodDelete, "/recipes/999", ``,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodPost, "/recipes/1/ingredients", `{"name":"salt","quantity":1,"unit":"g"}`,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		{http.MethodDelete, "/recipes/1/ingredients/999", ``,
+			[]int{401, 403, allowed, allowed, 401, 403, allowed, allowed, allowed}},
+		// batch PATCH ต้องมี role editor หรือ admin เสมอ API key อย่างเดียวไม่พอ
+		{http.MethodPatch, "/recipes/batch", `[]`,
+			[]int{401, 403, 401, 401, 401, 403, 403, allowed, allowed}},
+		// route อ่านข้อมูลไม่ต้องใช้ API key แต่ token ที่ไม่ถูกต้องยังได้ 401
+		{http.MethodGet, "/recipes", ``,
+			[]int{allowed, allowed, allowed, allowed, 401, allowed, allowed, allowed, allowed}},
+	}
+
+	for _, route := range routes {
+		for i, state := range states {
+			t.Run(route.method+" "+route.path+"/"+state.name, func(t *testing.T) {
+				w := server.do(route.method, route.path, route.body, state.headers...)
+				want := route.want[i]
+				if want == allowed {
+					if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+						t.Fatalf("status = %d, want the request to reach the handler; body %s", w.Code, w.Body.String())
+					}
+					return
+				}
+				expectStatus(t, w, want)
+			})
+		}
+	}
+}
+
+func TestRequireAPIKeyDisabled(t *testing.T) {
+	server := newTestServer(t, func(cfg *Config) {
+		cfg.APIKeys = nil
+		cfg.AuthDisabled = true
+	})
+	w := server.do(http.MethodPost, "/recipes", `{"name":"Open","description":"d","equipment":[]}`)
+	expectStatus(t, w, http.StatusCreated)
+}
+
+func TestCORSAllowsAPIKeyHeader(t *testing.T) {
+	server := newTestServer(t, func(cfg *Config) {
+		cfg.CORSAllowedOrigins = []string{"https://app.example"}
+	})
+	w := server.do(http.MethodOptions, "/recipes", "",
+		"Origin", "https://app.example",
+		"Access-Control-Request-Method", http.MethodPost,
+		"Access-Control-Request-Headers", apiKeyHeader)
+	expectStatus(t, w, http.StatusNoContent)
+	if got := w.Header().Get("Access-Control-Allow-Headers"); !containsHeader(got, apiKeyHeader) {
+		t.Fatalf("Access-Control-Allow-Headers = %q, want it to include %s", got, apiKeyHeader)
+	}
+}
+
+// containsHeader ตรวจสอบว่ารายการ header ที่คั่นด้วยจุลภาคมี name อยู่หรือไม่
+func containsHeader(list, name string) bool {
+	for _, value := range strings.Split(list, ",") {
+		if http.CanonicalHeaderKey(strings.TrimSpace(value)) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// audit บันทึกการกระทำที่ต้องตรวจสอบย้อนหลังได้ พร้อมผู้กระทำและผู้ที่ impersonate (ถ้ามี)
+func audit(c *gin.Context, format string, args ...interface{}) {
+	impersonator := ""
+	if claims := currentClaims(c); claims != nil {
+		impersonator = claims.ImpersonatedBy
+	}
+
+	slog.InfoContext(c.Request.Context(), "audit",
+		slog.String("actor", subject(c)),
+		slog.String("impersonated_by", impersonator),
+		slog.String("request_id", requestID(c)),
+		slog.String("action", sanitizeLogText(fmt.Sprintf(format, args...))))
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptCost คือค่า cost ที่ใช้ในการ hash รหัสผ่าน
+const bcryptCost = 12
+
+// maxPasswordBytes คือความยาวสูงสุดของรหัสผ่านที่ bcrypt รับได้ นับเป็น byte ไม่ใช่ตัวอักษร
+// รหัสผ่านภาษาไทยหนึ่งตัวอักษรใช้ 3 byte จึงยาวได้น้อยกว่า 72 ตัวอักษร
+const maxPasswordBytes = 72
+
+// dummyPasswordHash คือ hash ที่ authenticate ใช้เทียบเมื่อไม่พบผู้ใช้
+// เพื่อให้เวลาตอบของชื่อผู้ใช้ที่ไม่มีอยู่เท่ากับรหัสผ่านผิด และเดาไม่ได้ว่าชื่อใดลงทะเบียนไว้
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	// รหัสผ่านและ cost เป็นค่าคงที่ที่ถูกต้อง GenerateFromPassword จึงไม่คืน error
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcryptCost)
+	return hash
+})
+
+// tokenTTL คืออายุของ JWT ที่ออกให้หลัง login สำเร็จ
+const tokenTTL = 24 * time.Hour
+
+// impersonationTTL คืออายุของ JWT ที่ออกให้ผู้ดูแลระบบสวมสิทธิ์ผู้ใช้
+const impersonationTTL = 15 * time.Minute
+
+// บทบาทของผู้ใช้ที่มีสิทธิ์เพิ่มเติม
+const (
+	roleEditor = "editor"
+	roleAdmin  = "admin"
+)
+
+// claimsKey คือ key ที่ใช้เก็บ claims ของ JWT ใน Gin context
+const claimsKey = "claims"
+
+// ErrInvalidCredentials คือ error เมื่อชื่อผู้ใช้หรือรหัสผ่านไม่ถูกต้อง
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// User คือโครงสร้างที่แทนผู้ใช้ในระบบ
+type User struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	TenantID     string `json:"tenant_id"`
+	Role         string `json:"role"`
+	PasswordHash string `json:"-"`
+}
+
+// authClaims คือ claims ของ JWT ที่ระบบออกให้
+type authClaims struct {
+	TenantID string `json:"tenant_id,omitempty"`
+	Role     string `json:"role,omitempty"`
+	// ImpersonatedBy คือชื่อผู้ดูแลระบบที่ออก token นี้เพื่อสวมสิทธิ์ผู้ใช้
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// userStore คือ interface ที่กำหนดวิธีการจัดการกับข้อมูลของ User
+type userStore interface {
+	AddUser(ctx context.Context, user User) error
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+}
+
+// MySQLUserStore เป็น implement ของ userStore ที่ใช้ MySQL
+type MySQLUserStore struct {
+	db *sql.DB
+}
+
+// NewMySQLUserStore สร้าง instance ใหม่ของ MySQL user store
+func NewMySQLUserStore(db *sql.DB) userStore {
+	return &MySQLUserStore{db: db}
+}
+
+// AddUser เพิ่ม User เข้าสู่ฐานข้อมูล
+func (m *MySQLUserStore) AddUser(ctx context.Context, user User) error {
+	_, err := m.db.ExecContext(ctx, "INSERT INTO users (username, email, password_hash) VALUES (?, ?, ?)", user.Username, user.Email, user.PasswordHash)
+	if isDuplicateKey(err) {
+		field := duplicateField(err, "username")
+		value := user.Username
+		if field == "email" {
+			value = user.Email
+		}
+		return &ConflictError{Field: field, Value: value}
+	}
+	return err
+}
+
+// GetUserByUsername ดึงข้อมูล User จากฐานข้อมูลด้วยชื่อผู้ใช้
+func (m *MySQLUserStore) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	var user User
+	err := m.db.QueryRowContext(ctx, "SELECT id, username, email, tenant_id, role, password_hash FROM users WHERE username = ?", username).
+		Scan(&user.ID, &user.Username, &user.Email, &user.TenantID, &user.Role, &user.PasswordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, &NotFoundError{Resource: "user", Key: username}
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// AuthHandler เป็น handler สำหรับการสมัครสมาชิกและเข้าสู่ระบบ
+type AuthHandler struct {
+	users  userStore
+	secret []byte
+}
+
+// NewAuthHandler สร้าง instance ใหม่ของ AuthHandler
+func NewAuthHandler(users userStore, secret []byte) *AuthHandler {
+	return &AuthHandler{users: users, secret: secret}
+}
+
+// registerRequest คือ request body ของ POST /auth/register
+type registerRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// loginRequest คือ request body ของ POST /auth/login
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Register คือ handler สำหรับสมัครสมาชิกใหม่
+func (h *AuthHandler) Register(c *gin.Context) {
+	// ดึง request body และตรวจสอบความถูกต้อง
+	var req registerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if len(req.Password) > maxPasswordBytes {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes))
+		return
+	}
+
+	// hash รหัสผ่านก่อนบันทึก ห้ามเก็บรหัสผ่านแบบ plain text
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcryptCost)
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	user := User{Username: req.Username, Email: req.Email, PasswordHash: string(hash)}
+	err = h.users.AddUser(c.Request.Context(), user)
+	if err != nil {
+		// ไม่บอกว่าฟิลด์ใดซ้ำ เพื่อไม่ให้ใช้ตรวจสอบได้ว่าอีเมลใดลงทะเบียนไว้แล้ว
+		if errors.Is(err, ErrDuplicate) {
+			respondErr(c, http.StatusConflict, codeConflict, "username or email already exists")
+			return
+		}
+		respondStoreErr(c, err)
+		return
+	}
+
+	// ส่งข้อมูลผู้ใช้ที่สร้างแล้วกลับไป (ไม่รวมรหัสผ่าน)
+	RespondSuccess(c, http.StatusCreated, gin.H{"username": user.Username, "email": user.Email})
+}
+
+// Login คือ handler สำหรับเข้าสู่ระบบและออก JWT
+func (h *AuthHandler) Login(c *gin.Context) {
+	// ดึง request body และแปลงเป็นโครงสร้าง loginRequest
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.authenticate(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		if err == ErrInvalidCredentials {
+			respondErr(c, http.StatusUnauthorized, codeUnauthorized, err.Error())
+			return
+		}
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	// ส่ง token กลับไป
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_in": int(tokenTTL.Seconds()),
+	})
+}
+
+// impersonateRequest คือ request body ของ POST /admin/impersonate
+type impersonateRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// Impersonate คือ handler ที่ออก JWT อายุสั้นให้ผู้ดูแลระบบใช้งาน API ในฐานะผู้ใช้คนอื่น
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	// token ที่ได้จากการสวมสิทธิ์จะออก token สวมสิทธิ์ต่อไม่ได้
+	if claims := currentClaims(c); claims != nil && claims.ImpersonatedBy != "" {
+		respondErr(c, http.StatusForbidden, codeForbidden, "impersonated tokens cannot impersonate")
+		return
+	}
+
+	var req impersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(c.Request.Context(), req.UserID)
+	if err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+	// admin สวมสิทธิ์ได้เฉพาะผู้ใช้ใน tenant เดียวกัน ไม่มี admin ที่ข้าม tenant ได้
+	// ผู้ใช้ของ tenant อื่นตอบเหมือนไม่พบ เพื่อไม่ให้ใช้ตรวจสอบได้ว่ามีชื่อผู้ใช้นั้นใน tenant อื่น
+	if user.TenantID != tenantID(c) {
+		respondStoreErr(c, &NotFoundError{Resource: "user", Key: req.UserID})
+		return
+	}
+
+	token, err := h.issueToken(user, impersonationTTL, subject(c))
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	audit(c, "impersonated user %q", user.Username)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_in": int(impersonationTTL.Seconds()),
+	})
+}
+
+// authenticate ตรวจสอบชื่อผู้ใช้และรหัสผ่าน แล้วคืนค่า JWT ที่ลงนามแล้ว
+func (h *AuthHandler) authenticate(ctx context.Context, username, password string) (string, error) {
+	user, err := h.users.GetUserByUsername(ctx, username)
+	if errors.Is(err, ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", ErrInvalidCredentials
+	}
+
+	return h.issueToken(user, tokenTTL, "")
+}
+
+// issueToken สร้าง JWT ที่ลงนามด้วย HS256 สำหรับผู้ใช้
+// ถ้าระบุ impersonatedBy จะเป็น token ที่ผู้ดูแลระบบใช้สวมสิทธิ์ผู้ใช้
+func (h *AuthHandler) issueToken(user User, ttl time.Duration, impersonatedBy string) (string, error) {
+	now := time.Now()
+	claims := authClaims{
+		TenantID:       user.TenantID,
+		Role:           user.Role,
+		ImpersonatedBy: impersonatedBy,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.Username,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+}
+
+// parseToken ตรวจสอบลายเซ็นและอายุของ JWT แล้วคืนค่า claims
+func parseToken(secret []byte, tokenString string) (*authClaims, error) {
+	claims := &authClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// randomSecret สร้าง secret แบบสุ่มสำหรับใช้ตอนไม่ได้กำหนด JWT_SECRET
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+// currentClaims คืนค่า claims ของผู้เรียก หรือ nil ถ้า request ไม่ได้แนบ JWT
+func currentClaims(c *gin.Context) *authClaims {
+	claims, _ := c.Get(claimsKey)
+	authClaims, _ := claims.(*authClaims)
+	return authClaims
+}
+
+// subject คืนชื่อผู้ใช้ของผู้เรียก หรือ "anonymous" ถ้าไม่ได้เข้าสู่ระบบ
+func subject(c *gin.Context) string {
+	if claims := currentClaims(c); claims != nil && claims.Subject != "" {
+		return claims.Subject
+	}
+	return "anonymous"
+}
+
+// RequireAdmin อนุญาตเฉพาะผู้เรียกที่มี role เป็น admin
+func RequireAdmin() gin.HandlerFunc {
+	return RequireRole(roleAdmin)
+}
+
+// RequireRole อนุญาตเฉพาะผู้เรียกที่มี role ตรงกับรายการที่ระบุ
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims == nil {
+			respondErr(c, http.StatusUnauthorized, codeUnauthorized, "authentication required")
+			c.Abort()
+			return
+		}
+		if !containsString(roles, claims.Role) {
+			respondErr(c, http.StatusForbidden, codeForbidden, strings.Join(roles, " or ")+" role required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
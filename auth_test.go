@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAuthRouter(users userStore, secret []byte) *gin.Engine {
+	router := gin.New()
+	handler := NewAuthHandler(users, secret)
+	router.POST("/auth/register", handler.Register)
+	router.POST("/auth/login", handler.Login)
+	return router
+}
+
+func TestRegisterThenLogin(t *testing.T) {
+	secret := []byte("test-secret")
+	router := newAuthRouter(NewMemoryUserStore(), secret)
+
+	w := performRequest(router, http.MethodPost, "/auth/register", `{"username":"alice","email":"a@b.com","password":"secret"}`)
+	expectStatus(t, w, http.StatusCreated)
+
+	t.Run("wrong password", func(t *testing.T) {
+		w := performRequest(router, http.MethodPost, "/auth/login", `{"username":"alice","password":"wrong"}`)
+		expectStatus(t, w, http.StatusUnauthorized)
+		envelope := decodeEnvelope(t, w, nil)
+		if len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeUnauthorized {
+			t.Fatalf("errors = %+v, want one %s", envelope.Errors, codeUnauthorized)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		w := performRequest(router, http.MethodPost, "/auth/login", `{"username":"bob","password":"secret"}`)
+		expectStatus(t, w, http.StatusUnauthorized)
+	})
+
+	t.Run("correct password", func(t *testing.T) {
+		w := performRequest(router, http.MethodPost, "/auth/login", `{"username":"alice","password":"secret"}`)
+		expectStatus(t, w, http.StatusOK)
+
+		var data struct {
+			Token     string `json:"token"`
+			TokenType string `json:"token_type"`
+		}
+		decodeEnvelope(t, w, &data)
+		if data.TokenType != "Bearer" {
+			t.Errorf("token_type = %q, want Bearer", data.TokenType)
+		}
+		claims, err := parseToken(secret, data.Token)
+		if err != nil {
+			t.Fatalf("parse token: %v", err)
+		}
+		if claims.Subject != "alice" {
+			t.Errorf("subject = %q, want alice", claims.Subject)
+		}
+	})
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	router := newAuthRouter(NewMemoryUserStore(), []byte("test-secret"))
+
+	w := performRequest(router, http.MethodPost, "/auth/register", `{"username":"alice","email":"a@b.com","password":"secret"}`)
+	expectStatus(t, w, http.StatusCreated)
+
+	for _, body := range []string{
+		`{"username":"alice","email":"other@b.com","password":"secret"}`,
+		`{"username":"alice2","email":"A@B.com","password":"secret"}`,
+	} {
+		w := performRequest(router, http.MethodPost, "/auth/register", body)
+		expectStatus(t, w, http.StatusConflict)
+	}
+}
+
+func TestMemoryUserStoreNeverStoresPlainPassword(t *testing.T) {
+	users := NewMemoryUserStore()
+	router := newAuthRouter(users, []byte("test-secret"))
+
+	w := performRequest(router, http.MethodPost, "/auth/register", `{"username":"alice","email":"a@b.com","password":"secret"}`)
+	expectStatus(t, w, http.StatusCreated)
+
+	user, err := users.GetUserByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PasswordHash == "" || user.PasswordHash == "secret" {
+		t.Fatalf("password hash = %q, want a bcrypt hash", user.PasswordHash)
+	}
+}
+
+func TestRegisterLimitsPasswordBytes(t *testing.T) {
+	router := newAuthRouter(NewMemoryUserStore(), []byte("test-secret"))
+
+	// bcrypt นับความยาวเป็น byte ตัวอักษรไทยใช้ 3 byte จึงรับได้ไม่เกิน 24 ตัว
+	w := performRequest(router, http.MethodPost, "/auth/register", `{"username":"alice","email":"a@b.com","password":"`+strings.Repeat("ก", 30)+`"}`)
+	expectStatus(t, w, http.StatusBadRequest)
+	if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeBadRequest {
+		t.Errorf("errors = %+v, want one %s", envelope.Errors, codeBadRequest)
+	}
+
+	password := strings.Repeat("ก", maxPasswordBytes/3)
+	w = performRequest(router, http.MethodPost, "/auth/register", `{"username":"alice","email":"a@b.com","password":"`+password+`"}`)
+	expectStatus(t, w, http.StatusCreated)
+	w = performRequest(router, http.MethodPost, "/auth/login", `{"username":"alice","password":"`+password+`"}`)
+	expectStatus(t, w, http.StatusOK)
+}
+
+func TestLoginUnknownUserRunsBcrypt(t *testing.T) {
+	if cost, err := bcrypt.Cost(dummyPasswordHash()); err != nil || cost != bcryptCost {
+		t.Fatalf("dummy hash cost = %d, %v; want %d", cost, err, bcryptCost)
+	}
+	router := newAuthRouter(NewMemoryUserStore(), []byte("test-secret"))
+
+	start := time.Now()
+	bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte("secret"))
+	compare := time.Since(start)
+
+	// ผู้ใช้ที่ไม่มีอยู่ต้องใช้เวลาพอ ๆ กับการเทียบ bcrypt หนึ่งครั้ง ไม่ใช่ตอบกลับทันที
+	start = time.Now()
+	w := performRequest(router, http.MethodPost, "/auth/login", `{"username":"nobody","password":"secret"}`)
+	elapsed := time.Since(start)
+	expectStatus(t, w, http.StatusUnauthorized)
+	if elapsed < compare/2 {
+		t.Errorf("login for an unknown user took %s, a bcrypt comparison takes %s", elapsed, compare)
+	}
+}
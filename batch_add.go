@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchAddItems คือจำนวน Recipe สูงสุดที่ POST /recipes/batch รับได้ในครั้งเดียว
+const maxBatchAddItems = 500
+
+// BatchAddError คือ error ของ AddBatch ที่บอกว่ารายการใดทำให้ทั้ง batch ถูกยกเลิก
+type BatchAddError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *BatchAddError) Error() string {
+	return fmt.Sprintf("item %d (%q): %v", e.Index, e.Name, e.Err)
+}
+
+func (e *BatchAddError) Unwrap() error {
+	return e.Err
+}
+
+// AddBatch เพิ่ม Recipe หลายรายการใน transaction เดียว ถ้ารายการใดล้มเหลวจะไม่มีรายการใดถูกเพิ่ม
+// ความล้มเหลวของรายการคืนเป็น *BatchAddError และคืน id ของทุกรายการเรียงตามลำดับที่ส่งเข้ามาเมื่อสำเร็จ
+func (m *MySQLStore) AddBatch(ctx context.Context, tenantID string, recipes []Recipe) (_ []int64, err error) {
+	defer wrapStoreErr("AddBatch", time.Now(), &err)
+
+	ids := make([]int64, len(recipes))
+	err = m.withTx(ctx, func(tx *sql.Tx) error {
+		for i, recipe := range recipes {
+			id, err := addRecipeTx(ctx, tx, tenantID, recipe)
+			if err != nil {
+				return &BatchAddError{Index: i, Name: recipe.Name, Err: err}
+			}
+			ids[i] = id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BatchAddResponse คือผลลัพธ์ของ POST /recipes/batch
+type BatchAddResponse struct {
+	Created int     `json:"created"`
+	IDs     []int64 `json:"ids"`
+}
+
+// AddRecipesBatch คือ handler สำหรับเพิ่มสูตรอาหารหลายรายการในครั้งเดียวแบบทั้งหมดหรือไม่เลย
+func (h *RecipesHandler) AddRecipesBatch(c *gin.Context) {
+	// ดึง request body และแปลงเป็นรายการ Recipe
+	var entries []json.RawMessage
+	if err := c.ShouldBindJSON(&entries); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "batch must contain at least one item")
+		return
+	}
+	if len(entries) > maxBatchAddItems {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("batch must contain at most %d items", maxBatchAddItems))
+		return
+	}
+
+	// ตรวจสอบทุกรายการก่อน และรายงานทุกฟิลด์ที่ไม่ผ่านโดยนำหน้าด้วยลำดับของรายการ
+	recipes := make([]Recipe, len(entries))
+	var details []AppError
+	for i, entry := range entries {
+		prefix := strconv.Itoa(i) + "."
+		err := decodeStrictBytes(entry, &recipes[i])
+		var unknown *unknownFieldError
+		if errors.As(err, &unknown) {
+			details = append(details, AppError{Code: codeValidationFailed, Field: prefix + unknown.Field, Message: "is not a known field"})
+			continue
+		}
+		if err != nil {
+			respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("item %d: %v", i, err))
+			return
+		}
+		for _, detail := range h.validateRecipe(&recipes[i]) {
+			detail.Field = prefix + detail.Field
+			details = append(details, detail)
+		}
+	}
+	if len(details) > 0 {
+		RespondError(c, http.StatusUnprocessableEntity, details)
+		return
+	}
+
+	// เพิ่มทุกรายการใน transaction เดียว
+	ids, err := h.store.AddBatch(c.Request.Context(), tenantID(c), recipes)
+	var batchErr *BatchAddError
+	var conflict *ConflictError
+	if errors.As(err, &batchErr) && errors.As(err, &conflict) {
+		RespondError(c, http.StatusConflict, []AppError{{
+			Code:    codeConflict,
+			Field:   strconv.Itoa(batchErr.Index) + "." + conflict.Field,
+			Message: fmt.Sprintf("%s of the recipe at index %d already exists; no recipes were created", conflict.Field, batchErr.Index),
+		}})
+		return
+	}
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	RespondSuccess(c, http.StatusCreated, BatchAddResponse{Created: len(ids), IDs: ids})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAddRecipesBatch(t *testing.T) {
+	server := newTestServer(t, nil)
+	w := server.do(http.MethodPost, "/recipes/batch", `[
+		{"name":"Pad Thai","description":"d","equipment":[]},
+		{"name":"Tom Yum","description":"d","equipment":[]}
+	]`, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+	var response BatchAddResponse
+	decodeEnvelope(t, w, &response)
+	if response.Created != 2 || len(response.IDs) != 2 || response.IDs[0] != 1 || response.IDs[1] != 2 {
+		t.Errorf("response = %+v", response)
+	}
+	if got := recipeNames(server.listRecipes(t, "/recipes").Recipes); strings.Join(got, ",") != "Pad Thai,Tom Yum" {
+		t.Errorf("stored %v", got)
+	}
+}
+
+func TestAddRecipesBatchRollsBackOnDuplicate(t *testing.T) {
+	cases := []struct {
+		name, body, field string
+	}{
+		{"duplicate of an existing name", `[
+			{"name":"Green Curry","description":"d","equipment":[]},
+			{"name":"existing","description":"d","equipment":[]},
+			{"name":"Som Tam","description":"d","equipment":[]}
+		]`, "1.name"},
+		{"duplicate within the batch", `[
+			{"name":"Green Curry","description":"d","equipment":[]},
+			{"name":"Som Tam","description":"d","equipment":[]},
+			{"name":"green curry","description":"d","equipment":[]}
+		]`, "2.name"},
+		{"duplicate external_id", `[
+			{"name":"Green Curry","description":"d","equipment":[]},
+			{"name":"Som Tam","description":"d","equipment":[],"external_id":"ext-1","external_source":"partner"}
+		]`, "1.external_id"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			server.createRecipe(t, `{"name":"Existing","description":"d","equipment":[],"external_id":"ext-1","external_source":"partner"}`)
+
+			w := server.do(http.MethodPost, "/recipes/batch", tc.body, writeHeaders...)
+			expectStatus(t, w, http.StatusConflict)
+			envelope := decodeEnvelope(t, w, nil)
+			if len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeConflict || envelope.Errors[0].Field != tc.field {
+				t.Errorf("errors = %+v, want one conflict on %s", envelope.Errors, tc.field)
+			}
+
+			// รายการก่อนหน้ารายการที่ซ้ำต้องถูกยกเลิกด้วย
+			if page := server.listRecipes(t, "/recipes"); page.Total != 1 {
+				t.Errorf("stored %v after a rolled-back batch, want only the existing recipe", recipeNames(page.Recipes))
+			}
+		})
+	}
+}
+
+func TestAddRecipesBatchRejectsInvalidRequests(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	w := server.do(http.MethodPost, "/recipes/batch", `[]`, writeHeaders...)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	items := make([]string, maxBatchAddItems+1)
+	for i := range items {
+		items[i] = `{"name":"r` + strings.Repeat("x", i%7) + `","description":"d","equipment":[]}`
+	}
+	w = server.do(http.MethodPost, "/recipes/batch", "["+strings.Join(items, ",")+"]", writeHeaders...)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	// ทุกรายการที่ไม่ผ่านถูกรายงานพร้อมลำดับ และไม่มีรายการใดถูกเพิ่ม
+	w = server.do(http.MethodPost, "/recipes/batch", `[
+		{"name":"Pad Thai","description":"d","equipment":[]},
+		{"name":" ","description":"d","equipment":[]},
+		{"name":"Tom Yum","descripton":"d","equipment":[]}
+	]`, writeHeaders...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	var fields []string
+	for _, e := range decodeEnvelope(t, w, nil).Errors {
+		fields = append(fields, e.Field)
+	}
+	if strings.Join(fields, ",") != "1.name,2.descripton" {
+		t.Errorf("fields = %v", fields)
+	}
+	if page := server.listRecipes(t, "/recipes"); page.Total != 0 {
+		t.Errorf("stored %v after rejected batches", recipeNames(page.Recipes))
+	}
+}
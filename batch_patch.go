@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchPatchItems คือจำนวนรายการสูงสุดที่ PATCH /recipes/batch รับได้ในครั้งเดียว
+const maxBatchPatchItems = 100
+
+// สถานะผลลัพธ์ของแต่ละรายการใน batch
+const (
+	batchStatusUpdated          = "updated"
+	batchStatusNotFound         = "not_found"
+	batchStatusConflict         = "conflict"
+	batchStatusFrozen           = "frozen"
+	batchStatusValidationFailed = "validation_failed"
+	batchStatusRolledBack       = "rolled_back"
+)
+
+// RecipePatch คือการแก้ไขข้อมูล Recipe บางส่วน ฟิลด์ที่เป็น nil จะไม่ถูกเปลี่ยน
+// ส่วนสตริงว่างจะล้างค่าของฟิลด์ที่ไม่บังคับ
+type RecipePatch struct {
+	Name           *string   `json:"name"`
+	Description    *string   `json:"description"`
+	SourceURL      *string   `json:"source_url"`
+	SourceName     *string   `json:"source_name"`
+	ExternalID     *string   `json:"external_id"`
+	ExternalSource *string   `json:"external_source"`
+	Equipment      *[]string `json:"equipment"`
+
+	// Metadata แทนที่ metadata ทั้งหมดของ Recipe
+	Metadata *map[string]interface{} `json:"metadata"`
+}
+
+// isEmpty ตรวจสอบว่า patch ไม่ได้แก้ไขฟิลด์ใดเลย
+func (p RecipePatch) isEmpty() bool {
+	return p == RecipePatch{}
+}
+
+// apply คืนค่า Recipe ใหม่ที่ได้จากการนำ patch ไปใช้กับ recipe
+func (p RecipePatch) apply(recipe Recipe) Recipe {
+	if p.Name != nil {
+		recipe.Name = strings.TrimSpace(*p.Name)
+	}
+	if p.Description != nil {
+		recipe.Description = *p.Description
+	}
+	if p.SourceURL != nil {
+		recipe.SourceURL = *p.SourceURL
+	}
+	if p.SourceName != nil {
+		recipe.SourceName = *p.SourceName
+	}
+	if p.ExternalID != nil {
+		recipe.ExternalID = *p.ExternalID
+	}
+	if p.ExternalSource != nil {
+		recipe.ExternalSource = *p.ExternalSource
+	}
+	if p.Equipment != nil {
+		recipe.Equipment = normalizeEquipment(*p.Equipment)
+	}
+	if p.Metadata != nil {
+		recipe.Metadata = *p.Metadata
+	}
+	return recipe
+}
+
+// RecipePatchItem คือรายการหนึ่งใน batch ที่ระบุชื่อ Recipe และการแก้ไข
+type RecipePatchItem struct {
+	Name  string
+	Patch RecipePatch
+}
+
+// BatchItemResult คือผลลัพธ์ของแต่ละรายการใน batch เรียงตามลำดับที่ส่งเข้ามา
+type BatchItemResult struct {
+	Name    string     `json:"name"`
+	Status  string     `json:"status"`
+	Details []AppError `json:"details,omitempty"`
+}
+
+// PatchBatch แก้ไข Recipe หลายรายการ
+// ถ้า continueOnError เป็น false ทุกรายการจะอยู่ใน transaction เดียว และถ้ามีรายการใดล้มเหลวจะ rollback ทั้งหมด
+// ถ้าเป็น true แต่ละรายการจะ commit แยกกันและรายงานความล้มเหลวเป็นรายการ
+// error ที่คืนมาคือ error ของฐานข้อมูลที่ไม่ใช่ความล้มเหลวของรายการใดรายการหนึ่ง
+func (m *MySQLStore) PatchBatch(ctx context.Context, tenantID string, items []RecipePatchItem, continueOnError bool) (_ []BatchItemResult, err error) {
+	defer wrapStoreErr("PatchBatch", time.Now(), &err)
+
+	results := make([]BatchItemResult, len(items))
+
+	if continueOnError {
+		for i, item := range items {
+			err := m.withTx(ctx, func(tx *sql.Tx) error {
+				_, err := patchRecipeTx(ctx, tx, tenantID, "name", item.Name, item.Patch)
+				return err
+			})
+			result, ok := batchItemResult(item.Name, err)
+			if !ok {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	errBatchFailed := errors.New("batch failed")
+	err = m.withTx(ctx, func(tx *sql.Tx) error {
+		failed := false
+		for i, item := range items {
+			_, err := patchRecipeTx(ctx, tx, tenantID, "name", item.Name, item.Patch)
+			result, ok := batchItemResult(item.Name, err)
+			if !ok {
+				return err
+			}
+			results[i] = result
+			failed = failed || err != nil
+		}
+		if failed {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if err == errBatchFailed {
+		markRolledBack(results)
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Patch แก้ไขเฉพาะฟิลด์ที่มีใน patch ของ Recipe ที่มี id ตรงกัน และคืนค่า Recipe หลังแก้ไข
+func (m *MySQLStore) Patch(ctx context.Context, tenantID string, id int64, patch RecipePatch) (_ Recipe, err error) {
+	defer wrapStoreErr("Patch", time.Now(), &err)
+
+	// อ่านแถวใหม่หลังแก้ไขเพื่อให้ updated_at และวัตถุดิบตรงกับที่อยู่ในฐานข้อมูล
+	var recipe Recipe
+	err = m.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := patchRecipeTx(ctx, tx, tenantID, "id", id, patch); err != nil {
+			return err
+		}
+		var err error
+		recipe, err = m.loadRecipe(ctx, tx, tenantID, id)
+		return err
+	})
+	return recipe, err
+}
+
+// patchRecipeTx ล็อกแถวของ Recipe ที่ column (id หรือ name) เท่ากับ key แล้วนำ patch ไปใช้ภายใน transaction
+func patchRecipeTx(ctx context.Context, tx *sql.Tx, tenantID, column string, key interface{}, patch RecipePatch) (Recipe, error) {
+	recipe, err := scanRecipe(tx.QueryRowContext(ctx, "SELECT "+recipeColumns("description")+" FROM recipe WHERE "+column+" = ? AND tenant_id = ? FOR UPDATE", key, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Recipe{}, &NotFoundError{Resource: "recipe", Key: fmt.Sprint(key)}
+	}
+	if err != nil {
+		return Recipe{}, err
+	}
+
+	equipment, err := loadEquipment(ctx, tx, tenantID, []string{recipe.Name})
+	if err != nil {
+		return Recipe{}, err
+	}
+	recipe.Equipment = equipment[recipe.Name]
+
+	patched := patch.apply(recipe)
+	return patched, updateRecipeTx(ctx, tx, tenantID, recipe.ID, patched)
+}
+
+// batchItemResult แปลง error ของรายการเป็นผลลัพธ์ ok เป็น false ถ้า error ไม่ได้เป็นความล้มเหลวของรายการ
+func batchItemResult(name string, err error) (BatchItemResult, bool) {
+	var conflict *ConflictError
+	switch {
+	case err == nil:
+		return BatchItemResult{Name: name, Status: batchStatusUpdated}, true
+	case errors.Is(err, ErrFrozen):
+		return BatchItemResult{Name: name, Status: batchStatusFrozen, Details: []AppError{{Code: codeLocked, Message: sanitizeText(err.Error())}}}, true
+	case errors.Is(err, ErrNotFound):
+		return BatchItemResult{Name: name, Status: batchStatusNotFound}, true
+	case errors.As(err, &conflict):
+		return BatchItemResult{Name: name, Status: batchStatusConflict, Details: []AppError{{Code: codeConflict, Field: conflict.Field, Message: conflict.Field + " already exists"}}}, true
+	}
+	return BatchItemResult{}, false
+}
+
+// markRolledBack เปลี่ยนสถานะของรายการที่สำเร็จเป็น rolled_back เมื่อ transaction ของ batch ถูกยกเลิก
+func markRolledBack(results []BatchItemResult) {
+	for i := range results {
+		if results[i].Status == batchStatusUpdated || results[i].Status == "" {
+			results[i].Status = batchStatusRolledBack
+		}
+	}
+}
+
+// batchPatchEntry คือรูปแบบของแต่ละรายการใน request body ของ PATCH /recipes/batch
+type batchPatchEntry struct {
+	Name  string          `json:"name"`
+	Patch json.RawMessage `json:"patch"`
+}
+
+// BatchPatchResponse คือผลลัพธ์ของ PATCH /recipes/batch
+type BatchPatchResponse struct {
+	Committed bool              `json:"committed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+// PatchRecipesBatch คือ handler สำหรับแก้ไขสูตรอาหารหลายรายการในครั้งเดียว
+func (h *RecipesHandler) PatchRecipesBatch(c *gin.Context) {
+	continueOnError := false
+	if v := c.Query("continue_on_error"); v != "" {
+		var err error
+		continueOnError, err = strconv.ParseBool(v)
+		if err != nil {
+			respondErr(c, http.StatusBadRequest, codeBadRequest, "continue_on_error must be a boolean")
+			return
+		}
+	}
+
+	// ดึง request body และแปลงเป็นรายการที่ต้องแก้ไข
+	var entries []batchPatchEntry
+	if err := c.ShouldBindJSON(&entries); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "batch must contain at least one item")
+		return
+	}
+	if len(entries) > maxBatchPatchItems {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("batch must contain at most %d items", maxBatchPatchItems))
+		return
+	}
+
+	// ตรวจสอบแต่ละรายการก่อน รายการที่ไม่ผ่านจะไม่ถูกส่งไปยัง store
+	results := make([]BatchItemResult, len(entries))
+	var items []RecipePatchItem
+	var positions []int
+	for i, entry := range entries {
+		patch, details := h.decodePatch(entry)
+		if len(details) > 0 {
+			results[i] = BatchItemResult{Name: sanitizeText(entry.Name), Status: batchStatusValidationFailed, Details: details}
+			continue
+		}
+		items = append(items, RecipePatchItem{Name: entry.Name, Patch: patch})
+		positions = append(positions, i)
+	}
+
+	// ในโหมด transaction เดียว ถ้ามีรายการที่ไม่ผ่านการตรวจสอบจะไม่แก้ไขรายการใดเลย
+	if !continueOnError && len(items) < len(entries) {
+		markRolledBack(results)
+		RespondSuccess(c, http.StatusOK, BatchPatchResponse{Results: results})
+		return
+	}
+
+	if len(items) > 0 {
+		storeResults, err := h.store.PatchBatch(c.Request.Context(), tenantID(c), items, continueOnError)
+		if err != nil {
+			respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+			return
+		}
+		for i, result := range storeResults {
+			results[positions[i]] = result
+		}
+	}
+
+	// บันทึก audit หนึ่งรายการต่อ Recipe ที่ถูกแก้ไขจริง
+	committed := false
+	for _, result := range results {
+		if result.Status == batchStatusUpdated {
+			committed = true
+			audit(c, "updated recipe %q via batch", result.Name)
+		}
+	}
+
+	RespondSuccess(c, http.StatusOK, BatchPatchResponse{Committed: committed, Results: results})
+}
+
+// decodePatch แปลงและตรวจสอบ patch ของรายการ โดยคืนรายละเอียดของข้อผิดพลาดถ้าไม่ผ่าน
+func (h *RecipesHandler) decodePatch(entry batchPatchEntry) (RecipePatch, []AppError) {
+	var details []AppError
+	if entry.Name == "" {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "name", Message: "name is required"})
+	}
+
+	var patch RecipePatch
+	if len(entry.Patch) == 0 {
+		return patch, append(details, AppError{Code: codeValidationFailed, Field: "patch", Message: "patch is required"})
+	}
+	decoder := json.NewDecoder(bytes.NewReader(entry.Patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		return patch, append(details, AppError{Code: codeValidationFailed, Field: "patch", Message: sanitizeText(err.Error())})
+	}
+	if patch.isEmpty() {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "patch", Message: "patch must change at least one field"})
+	}
+	return patch, append(details, h.validatePatch(patch)...)
+}
+
+// validatePatch ตรวจสอบฟิลด์ที่มีใน patch ด้วยกฎเดียวกับ validateRecipe
+func (h *RecipesHandler) validatePatch(patch RecipePatch) []AppError {
+	var details []AppError
+	if patch.Name != nil {
+		details = append(details, validateName(strings.TrimSpace(*patch.Name))...)
+	}
+	if patch.Description != nil && !h.limits.descriptionFits(*patch.Description) {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "description", Message: h.limits.descriptionTooLong()})
+	}
+	if patch.Equipment != nil {
+		details = append(details, validateEquipment(*patch.Equipment)...)
+	}
+	if patch.SourceURL != nil {
+		details = append(details, validateLength("source_url", *patch.SourceURL, maxSourceURLRunes)...)
+	}
+	if patch.SourceName != nil {
+		details = append(details, validateLength("source_name", *patch.SourceName, maxSourceNameRunes)...)
+	}
+	// external_id และ external_source ต้องแก้พร้อมกัน เพื่อไม่ให้เหลือคู่ที่มีค่าเพียงฝั่งเดียว
+	if patch.ExternalID != nil || patch.ExternalSource != nil {
+		var externalID, externalSource string
+		if patch.ExternalID != nil {
+			externalID = *patch.ExternalID
+		}
+		if patch.ExternalSource != nil {
+			externalSource = *patch.ExternalSource
+		}
+		details = append(details, validateExternalID(externalID, externalSource)...)
+	}
+	if patch.Metadata != nil {
+		details = append(details, h.metadata.validate(*patch.Metadata)...)
+	}
+	return details
+}
+
+// PatchRecipe คือ handler สำหรับแก้ไขสูตรอาหารบางฟิลด์ ฟิลด์ที่ไม่ได้ส่งมาจะไม่ถูกเปลี่ยน
+func (h *RecipesHandler) PatchRecipe(c *gin.Context) {
+	// ดึงพารามิเตอร์ URL
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	// ดึง request body และแปลงเป็น RecipePatch ทั้งแบบ JSON ปกติและแบบ JSON:API
+	var patch RecipePatch
+	err := bindRecipe(c, &patch)
+	var unknown *unknownFieldError
+	if errors.As(err, &unknown) {
+		RespondError(c, http.StatusUnprocessableEntity, []AppError{{Code: codeValidationFailed, Field: unknown.Field, Message: "is not a known field"}})
+		return
+	}
+	if err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if patch.isEmpty() {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "patch must change at least one field")
+		return
+	}
+	if details := h.validatePatch(patch); len(details) > 0 {
+		RespondError(c, http.StatusUnprocessableEntity, details)
+		return
+	}
+
+	// เรียกใช้ store เพื่อแก้ไขสูตรอาหาร
+	recipe, err := h.store.Patch(c.Request.Context(), tenantID(c), id, patch)
+	if err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+
+	// ส่งข้อมูลสูตรอาหารที่แก้ไขแล้วกลับไป
+	RespondSuccess(c, http.StatusOK, recipe)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config คือค่าตั้งของฐานข้อมูลและเซิร์ฟเวอร์ที่อ่านจาก environment
+type Config struct {
+	DBHost     string
+	DBPort     int
+	DBUser     string
+	DBPassword string
+	DBName     string
+
+	// MaxOpenConns เป็น 0 หมายถึงไม่จำกัด
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// RequestTimeout คือเวลาสูงสุดที่แต่ละ request ทำงานได้ก่อนได้รับ 504
+	RequestTimeout time.Duration
+
+	// ListenAddr คือ address ที่เซิร์ฟเวอร์ HTTP รอรับการเชื่อมต่อ เช่น :8081
+	ListenAddr string
+
+	// ShutdownTimeout คือเวลาที่รอ request ที่กำลังทำงานให้เสร็จเมื่อได้รับ SIGTERM
+	ShutdownTimeout time.Duration
+
+	// LogLevel และ LogFormat (json หรือ text) กำหนดรูปแบบของ log
+	LogLevel  slog.Level
+	LogFormat string
+
+	// Limits อ่านจาก MAX_DESCRIPTION_LENGTH และ DESCRIPTION_SUMMARY_LENGTH
+	Limits RecipeLimits
+
+	// StoreBackend คือ mysql หรือ memory
+	StoreBackend string
+
+	// MigrateOnStart รัน migration ตอนเริ่มเซิร์ฟเวอร์ และรอ lock ของ migration ได้ไม่เกิน MigrationLockTimeout
+	MigrateOnStart       bool
+	MigrationLockTimeout time.Duration
+
+	// JWTSecret ว่างหมายถึงสุ่ม secret ใหม่ทุกครั้งที่เริ่มเซิร์ฟเวอร์
+	JWTSecret string
+
+	// MultiTenant เป็น false หมายถึงทุก request ใช้ข้อมูลของ tenant เริ่มต้นเท่านั้น
+	MultiTenant bool
+
+	// MetadataSchemaFile และ UnitsFile คือไฟล์ JSON ที่อ่านตอนเริ่มเซิร์ฟเวอร์ ว่างหมายถึงใช้ค่าเริ่มต้น
+	MetadataSchemaFile string
+	UnitsFile          string
+
+	// APIKeys และ APIKeysFile คือ API key ที่เขียนข้อมูลได้ AuthDisabled ปิดการตรวจสอบ API key ทั้งหมด
+	APIKeys      []string
+	APIKeysFile  string
+	AuthDisabled bool
+
+	// CORSAllowedOrigins คือ origin ที่ browser เรียก API ได้ "*" หมายถึงทุก origin
+	CORSAllowedOrigins []string
+}
+
+// LoadConfig อ่าน Config จาก environment โดยใช้ค่าเริ่มต้นกับตัวแปรที่ไม่ได้กำหนด
+// ค่าที่กำหนดไว้แต่ไม่ถูกต้องจะคืน error ที่บอกชื่อตัวแปร แทนที่จะใช้ค่าเริ่มต้นแบบเงียบ ๆ
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		DBHost:     envString("DB_HOST", "127.0.0.1"),
+		DBUser:     envString("DB_USER", "root"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     envString("DB_NAME", "web_lek"),
+		ListenAddr: envString("LISTEN_ADDR", ":8081"),
+		LogFormat:  envString("LOG_FORMAT", logFormatJSON),
+
+		StoreBackend:       envString("STORE_BACKEND", "mysql"),
+		JWTSecret:          os.Getenv("JWT_SECRET"),
+		MetadataSchemaFile: os.Getenv("METADATA_SCHEMA_FILE"),
+		UnitsFile:          os.Getenv("UNITS_FILE"),
+		APIKeys:            envList("API_KEYS"),
+		APIKeysFile:        os.Getenv("API_KEYS_FILE"),
+		CORSAllowedOrigins: envList("CORS_ALLOWED_ORIGINS"),
+	}
+
+	var err error
+	if cfg.MigrateOnStart, err = envBool("MIGRATE_ON_START"); err != nil {
+		return Config{}, err
+	}
+	if cfg.MultiTenant, err = envBool("MULTI_TENANT"); err != nil {
+		return Config{}, err
+	}
+	if cfg.AuthDisabled, err = envBool("AUTH_DISABLED"); err != nil {
+		return Config{}, err
+	}
+	if cfg.DBPort, err = envIntStrict("DB_PORT", 3306); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxOpenConns, err = envIntStrict("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxIdleConns, err = envIntStrict("DB_MAX_IDLE_CONNS", 25); err != nil {
+		return Config{}, err
+	}
+	if cfg.ConnMaxLifetime, err = envDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.RequestTimeout, err = envDuration("REQUEST_TIMEOUT", 5*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.ShutdownTimeout, err = envDuration("SHUTDOWN_TIMEOUT", 15*time.Second); err != nil {
+		return Config{}, err
+	}
+	lockSeconds, err := envIntStrict("MIGRATION_LOCK_TIMEOUT", int(defaultMigrationLockTimeout/time.Second))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.MigrationLockTimeout = time.Duration(lockSeconds) * time.Second
+	if cfg.Limits.MaxDescriptionRunes, err = envIntStrict("MAX_DESCRIPTION_LENGTH", defaultMaxDescriptionRunes); err != nil {
+		return Config{}, err
+	}
+	if cfg.Limits.SummaryRunes, err = envIntStrict("DESCRIPTION_SUMMARY_LENGTH", defaultSummaryRunes); err != nil {
+		return Config{}, err
+	}
+	if cfg.LogLevel, err = parseLogLevel(envString("LOG_LEVEL", "info")); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.validate()
+}
+
+// validate ตรวจสอบว่าค่าที่จำเป็นครบและอยู่ในช่วงที่ใช้ได้
+func (cfg Config) validate() error {
+	if cfg.DBUser == "" {
+		return fmt.Errorf("config: DB_USER must not be empty")
+	}
+	if cfg.DBName == "" {
+		return fmt.Errorf("config: DB_NAME must not be empty")
+	}
+	if cfg.DBPort < 1 || cfg.DBPort > 65535 {
+		return fmt.Errorf("config: DB_PORT must be between 1 and 65535, got %d", cfg.DBPort)
+	}
+	if cfg.MaxOpenConns < 0 {
+		return fmt.Errorf("config: DB_MAX_OPEN_CONNS must not be negative, got %d", cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns < 0 {
+		return fmt.Errorf("config: DB_MAX_IDLE_CONNS must not be negative, got %d", cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime < 0 {
+		return fmt.Errorf("config: DB_CONN_MAX_LIFETIME must not be negative, got %s", cfg.ConnMaxLifetime)
+	}
+	if cfg.RequestTimeout <= 0 {
+		return fmt.Errorf("config: REQUEST_TIMEOUT must be positive, got %s", cfg.RequestTimeout)
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		return fmt.Errorf("config: SHUTDOWN_TIMEOUT must be positive, got %s", cfg.ShutdownTimeout)
+	}
+	if cfg.StoreBackend != "mysql" && cfg.StoreBackend != "memory" {
+		return fmt.Errorf("config: STORE_BACKEND must be mysql or memory, got %q", cfg.StoreBackend)
+	}
+	if cfg.MigrationLockTimeout <= 0 {
+		return fmt.Errorf("config: MIGRATION_LOCK_TIMEOUT must be a positive number of seconds, got %s", cfg.MigrationLockTimeout)
+	}
+	if cfg.Limits.MaxDescriptionRunes <= 0 {
+		return fmt.Errorf("config: MAX_DESCRIPTION_LENGTH must be positive, got %d", cfg.Limits.MaxDescriptionRunes)
+	}
+	if cfg.Limits.SummaryRunes <= 0 {
+		return fmt.Errorf("config: DESCRIPTION_SUMMARY_LENGTH must be positive, got %d", cfg.Limits.SummaryRunes)
+	}
+	if cfg.LogFormat != logFormatJSON && cfg.LogFormat != logFormatText {
+		return fmt.Errorf("config: LOG_FORMAT must be json or text, got %q", cfg.LogFormat)
+	}
+	if _, _, err := net.SplitHostPort(cfg.ListenAddr); err != nil {
+		return fmt.Errorf("config: LISTEN_ADDR %q is not a valid host:port: %v", cfg.ListenAddr, err)
+	}
+	return nil
+}
+
+// envString อ่านตัวแปร environment หรือคืน fallback ถ้าไม่ได้กำหนด
+func envString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// envList อ่านรายการที่คั่นด้วยจุลภาคจากตัวแปร environment โดยตัดช่องว่างและค่าว่างออก
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// envIntStrict อ่านจำนวนเต็มจากตัวแปร environment หรือคืน fallback ถ้าไม่ได้กำหนด
+// ค่าที่อ่านไม่ได้จะคืน error
+func envIntStrict(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s must be an integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+// envBool อ่านค่า true หรือ false จากตัวแปร environment ตามรูปแบบของ strconv.ParseBool เช่น 1, TRUE หรือ false
+// ถ้าไม่ได้กำหนดจะคืน false ส่วนค่าอื่น เช่น yes จะคืน error แทนที่จะถือว่าเป็น false แบบเงียบ ๆ
+func envBool(key string) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("config: %s must be true or false, got %q", key, v)
+	}
+	return b, nil
+}
+
+// envDuration อ่านระยะเวลา เช่น 5m หรือ 30s จากตัวแปร environment หรือคืน fallback ถ้าไม่ได้กำหนด
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s must be a duration such as 5m, got %q", key, v)
+	}
+	return d, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// configEnv คือตัวแปร environment ทั้งหมดที่ LoadConfig อ่าน
+var configEnv = []string{
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME",
+	"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME",
+	"LISTEN_ADDR", "REQUEST_TIMEOUT", "SHUTDOWN_TIMEOUT",
+	"LOG_LEVEL", "LOG_FORMAT", "MAX_DESCRIPTION_LENGTH", "DESCRIPTION_SUMMARY_LENGTH",
+	"STORE_BACKEND", "MIGRATE_ON_START", "MIGRATION_LOCK_TIMEOUT", "JWT_SECRET", "MULTI_TENANT",
+	"METADATA_SCHEMA_FILE", "UNITS_FILE", "API_KEYS", "API_KEYS_FILE", "AUTH_DISABLED",
+	"CORS_ALLOWED_ORIGINS",
+}
+
+// clearConfigEnv ล้างตัวแปร environment ของ LoadConfig เพื่อไม่ให้ค่าจากเครื่องที่รัน test ปนเข้ามา
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range configEnv {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	clearConfigEnv(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBHost != "127.0.0.1" || cfg.DBPort != 3306 || cfg.DBUser != "root" || cfg.DBName != "web_lek" {
+		t.Errorf("database defaults = %s@%s:%d/%s", cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
+	}
+	if cfg.ListenAddr != ":8081" {
+		t.Errorf("ListenAddr = %q, want :8081", cfg.ListenAddr)
+	}
+	if cfg.MaxOpenConns != 25 || cfg.MaxIdleConns != 25 || cfg.ConnMaxLifetime != 5*time.Minute {
+		t.Errorf("pool = %d open, %d idle, %s lifetime", cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime)
+	}
+	if cfg.Limits.MaxDescriptionRunes != defaultMaxDescriptionRunes {
+		t.Errorf("MaxDescriptionRunes = %d, want %d", cfg.Limits.MaxDescriptionRunes, defaultMaxDescriptionRunes)
+	}
+	if cfg.MigrationLockTimeout != defaultMigrationLockTimeout {
+		t.Errorf("MigrationLockTimeout = %s, want %s", cfg.MigrationLockTimeout, defaultMigrationLockTimeout)
+	}
+	if cfg.StoreBackend != "mysql" || cfg.MigrateOnStart || cfg.MultiTenant || cfg.AuthDisabled {
+		t.Errorf("unexpected feature defaults: %+v", cfg)
+	}
+}
+
+func TestLoadConfigReadsEnvironment(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PORT", "3307")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "90s")
+	t.Setenv("LISTEN_ADDR", "127.0.0.1:9000")
+	t.Setenv("MIGRATION_LOCK_TIMEOUT", "5")
+	t.Setenv("UNITS_FILE", "units.json")
+	t.Setenv("METADATA_SCHEMA_FILE", "schema.json")
+	t.Setenv("API_KEYS", " key-1, ,key-2 ")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example,https://b.example")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBHost != "db.internal" || cfg.DBPort != 3307 || cfg.DBPassword != "secret" {
+		t.Errorf("database = %s:%d password %q", cfg.DBHost, cfg.DBPort, cfg.DBPassword)
+	}
+	if cfg.ConnMaxLifetime != 90*time.Second {
+		t.Errorf("ConnMaxLifetime = %s, want 90s", cfg.ConnMaxLifetime)
+	}
+	if cfg.ListenAddr != "127.0.0.1:9000" {
+		t.Errorf("ListenAddr = %q", cfg.ListenAddr)
+	}
+	if cfg.MigrationLockTimeout != 5*time.Second {
+		t.Errorf("MigrationLockTimeout = %s, want 5s", cfg.MigrationLockTimeout)
+	}
+	if cfg.UnitsFile != "units.json" || cfg.MetadataSchemaFile != "schema.json" {
+		t.Errorf("files = %q, %q", cfg.UnitsFile, cfg.MetadataSchemaFile)
+	}
+	if strings.Join(cfg.APIKeys, "|") != "key-1|key-2" {
+		t.Errorf("APIKeys = %q", cfg.APIKeys)
+	}
+	if len(cfg.CORSAllowedOrigins) != 2 {
+		t.Errorf("CORSAllowedOrigins = %q", cfg.CORSAllowedOrigins)
+	}
+}
+
+func TestLoadConfigRejectsInvalidValues(t *testing.T) {
+	cases := []struct {
+		key, value string
+	}{
+		{"DB_PORT", "mysql"},
+		{"DB_PORT", "0"},
+		{"DB_PORT", "70000"},
+		{"DB_MAX_OPEN_CONNS", "-1"},
+		{"DB_MAX_IDLE_CONNS", "many"},
+		{"DB_CONN_MAX_LIFETIME", "5"},
+		{"REQUEST_TIMEOUT", "0s"},
+		{"SHUTDOWN_TIMEOUT", "soon"},
+		{"LISTEN_ADDR", "8081"},
+		{"LOG_LEVEL", "loud"},
+		{"LOG_FORMAT", "xml"},
+		{"STORE_BACKEND", "postgres"},
+		{"MIGRATION_LOCK_TIMEOUT", "0"},
+		{"MIGRATION_LOCK_TIMEOUT", "30s"},
+		{"MAX_DESCRIPTION_LENGTH", "-5"},
+		{"MAX_DESCRIPTION_LENGTH", "99999999999999999999"},
+		{"DESCRIPTION_SUMMARY_LENGTH", "0"},
+		{"MIGRATE_ON_START", "yes"},
+		{"MULTI_TENANT", "on"},
+		{"AUTH_DISABLED", "disabled"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.key+"="+tc.value, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv(tc.key, tc.value)
+
+			_, err := LoadConfig()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			// error ต้องบอกชื่อตัวแปรที่ผิด เพื่อให้แก้ค่าได้จาก log ตอนเริ่มเซิร์ฟเวอร์
+			if !strings.Contains(err.Error(), tc.key) {
+				t.Errorf("error %q does not name %s", err, tc.key)
+			}
+		})
+	}
+}
+
+func TestLoadConfigParsesBooleans(t *testing.T) {
+	for _, value := range []string{"1", "t", "TRUE", "True", "true"} {
+		clearConfigEnv(t)
+		t.Setenv("MIGRATE_ON_START", value)
+		t.Setenv("MULTI_TENANT", value)
+		t.Setenv("AUTH_DISABLED", value)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("%s: %v", value, err)
+		}
+		if !cfg.MigrateOnStart || !cfg.MultiTenant || !cfg.AuthDisabled {
+			t.Errorf("%s: MigrateOnStart %v MultiTenant %v AuthDisabled %v, want all true", value, cfg.MigrateOnStart, cfg.MultiTenant, cfg.AuthDisabled)
+		}
+	}
+	for _, value := range []string{"0", "false", "FALSE"} {
+		clearConfigEnv(t)
+		t.Setenv("MULTI_TENANT", value)
+		cfg, err := LoadConfig()
+		if err != nil || cfg.MultiTenant {
+			t.Errorf("MULTI_TENANT=%s: MultiTenant %v, %v; want false", value, cfg.MultiTenant, err)
+		}
+	}
+}
+
+func TestLoadConfigRequiresDatabaseSettings(t *testing.T) {
+	// DB_USER และ DB_NAME มีค่าเริ่มต้น จึงตรวจค่าว่างผ่าน validate โดยตรง
+	clearConfigEnv(t)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missingUser := cfg
+	missingUser.DBUser = ""
+	if err := missingUser.validate(); err == nil || !strings.Contains(err.Error(), "DB_USER") {
+		t.Errorf("validate() = %v, want an error naming DB_USER", err)
+	}
+	missingName := cfg
+	missingName.DBName = ""
+	if err := missingName.validate(); err == nil || !strings.Contains(err.Error(), "DB_NAME") {
+		t.Errorf("validate() = %v, want an error naming DB_NAME", err)
+	}
+}
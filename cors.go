@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsMaxAge คือระยะเวลา (วินาที) ที่ browser เก็บผลลัพธ์ของ preflight ไว้ได้
+const corsMaxAge = 86400
+
+// CORSConfig คือการตั้งค่า CORS ของเซิร์ฟเวอร์
+type CORSConfig struct {
+	// AllowedOrigins คือรายการ origin ที่อนุญาต ใช้ "*" เพื่ออนุญาตทุก origin
+	AllowedOrigins []string
+	// AllowedHeaders คือ request header ที่ browser ส่งมาได้
+	AllowedHeaders []string
+	// ExposedHeaders คือ response header ที่ script ใน browser อ่านได้
+	ExposedHeaders []string
+}
+
+// LoadCORSConfig สร้าง CORSConfig จาก cfg.CORSAllowedOrigins
+func LoadCORSConfig(cfg Config) CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: cfg.CORSAllowedOrigins,
+		AllowedHeaders: []string{"Authorization", "Content-Type", apiKeyHeader, requestIDHeader},
+		ExposedHeaders: []string{"Location", requestIDHeader},
+	}
+}
+
+// allowsOrigin ตรวจสอบว่า origin อยู่ในรายการที่อนุญาตหรือไม่
+func (cfg CORSConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware ใส่ Access-Control-Allow-Origin ให้ทุก response ของ request ที่มาจาก origin ที่อนุญาต
+// preflight อย่างเดียวไม่พอ browser ต้องเห็น header นี้ใน response จริงด้วยจึงจะให้ script อ่านได้
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if origin := c.GetHeader("Origin"); origin != "" {
+			c.Writer.Header().Add("Vary", "Origin")
+			if cfg.allowsOrigin(origin) {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Header("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+			}
+		}
+		c.Next()
+	}
+}
+
+// corsPreflightHandler สร้าง handler สำหรับ OPTIONS preflight ของ route ที่รองรับ methods ที่ระบุ
+func corsPreflightHandler(cfg CORSConfig, methods ...string) gin.HandlerFunc {
+	allowMethods := strings.Join(append(methods, http.MethodOptions), ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		requestMethod := c.GetHeader("Access-Control-Request-Method")
+
+		// OPTIONS ที่ไม่ใช่ CORS preflight ตอบเพียง method ที่รองรับ
+		if origin == "" || requestMethod == "" {
+			c.Header("Allow", allowMethods)
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		if !cfg.allowsOrigin(origin) {
+			respondErr(c, http.StatusForbidden, codeForbidden, "origin not allowed")
+			return
+		}
+		if !containsString(methods, requestMethod) {
+			respondErr(c, http.StatusForbidden, codeForbidden, "method not allowed")
+			return
+		}
+
+		// Vary และ Access-Control-Allow-Origin ถูกใส่โดย CORSMiddleware แล้ว
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+		c.Header("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// containsString ตรวจสอบว่า values มี s อยู่หรือไม่
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testOrigin คือ origin ที่ test ของ CORS อนุญาต
+const testOrigin = "https://app.example"
+
+// newCORSTestServer สร้างเซิร์ฟเวอร์ที่อนุญาตเฉพาะ testOrigin
+func newCORSTestServer(t *testing.T) *testServer {
+	t.Helper()
+	return newTestServer(t, func(cfg *Config) {
+		cfg.CORSAllowedOrigins = []string{testOrigin}
+	})
+}
+
+func TestCORSPreflightForUpdate(t *testing.T) {
+	server := newCORSTestServer(t)
+	w := server.do(http.MethodOptions, "/recipes/1", "",
+		"Origin", testOrigin,
+		"Access-Control-Request-Method", http.MethodPut)
+	expectStatus(t, w, http.StatusNoContent)
+
+	headers := []struct{ name, want string }{
+		{"Access-Control-Allow-Origin", testOrigin},
+		{"Access-Control-Max-Age", "86400"},
+		{"Vary", "Origin"},
+	}
+	for _, h := range headers {
+		if got := w.Header().Get(h.name); got != h.want {
+			t.Errorf("%s = %q, want %q", h.name, got, h.want)
+		}
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); !containsHeader(got, http.MethodPut) {
+		t.Errorf("Access-Control-Allow-Methods = %q, want it to include PUT", got)
+	}
+	for _, name := range []string{"Authorization", "Content-Type"} {
+		if got := w.Header().Get("Access-Control-Allow-Headers"); !containsHeader(got, name) {
+			t.Errorf("Access-Control-Allow-Headers = %q, want it to include %s", got, name)
+		}
+	}
+}
+
+func TestCORSPreflightRejections(t *testing.T) {
+	server := newCORSTestServer(t)
+	cases := []struct {
+		name    string
+		path    string
+		headers []string
+		status  int
+	}{
+		{"unknown origin", "/recipes/1", []string{"Origin", "https://evil.example", "Access-Control-Request-Method", http.MethodPut}, http.StatusForbidden},
+		{"unsupported method", "/recipes", []string{"Origin", testOrigin, "Access-Control-Request-Method", http.MethodDelete}, http.StatusForbidden},
+		{"plain OPTIONS", "/recipes", nil, http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(http.MethodOptions, tc.path, "", tc.headers...)
+			expectStatus(t, w, tc.status)
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+				t.Errorf("Access-Control-Allow-Methods = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestCORSHeadersOnActualResponses(t *testing.T) {
+	server := newCORSTestServer(t)
+	cases := []struct {
+		name       string
+		method     string
+		path, body string
+		origin     string
+		allowed    bool
+	}{
+		{"GET from allowed origin", http.MethodGet, "/recipes", "", testOrigin, true},
+		{"POST from allowed origin", http.MethodPost, "/recipes", `{"name":"Pad Thai","description":"d","equipment":[]}`, testOrigin, true},
+		{"error from allowed origin", http.MethodGet, "/recipes/999", "", testOrigin, true},
+		{"GET from unknown origin", http.MethodGet, "/recipes", "", "https://evil.example", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(tc.method, tc.path, tc.body, append([]string{"Origin", tc.origin}, writeHeaders...)...)
+			got := w.Header().Get("Access-Control-Allow-Origin")
+			if tc.allowed && got != tc.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tc.origin)
+			}
+			if !tc.allowed && got != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Origin" {
+				t.Errorf("Vary = %q, want Origin", vary)
+			}
+		})
+	}
+
+	// request ที่ไม่มี Origin ไม่ได้มาจาก browser จึงไม่ต้องมี header CORS
+	w := server.do(http.MethodGet, "/recipes", "")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin without Origin = %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EquipmentCount คือชื่ออุปกรณ์และจำนวนสูตรอาหารที่ใช้อุปกรณ์นั้น
+type EquipmentCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// normalizeEquipment ตัดช่องว่าง ลบชื่อว่าง และลบชื่อที่ซ้ำกันออกจากรายการอุปกรณ์
+// ชื่อที่ต่างกันแค่ตัวพิมพ์ถือว่าซ้ำกันเหมือน primary key ของ recipe_equipment และจะเก็บชื่อแรกไว้
+func normalizeEquipment(equipment []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, name := range equipment {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// insertEquipment เพิ่มรายการอุปกรณ์ของ Recipe ภายใน transaction
+func insertEquipment(ctx context.Context, tx *sql.Tx, tenantID, recipeName string, equipment []string) error {
+	for _, name := range equipment {
+		_, err := tx.ExecContext(ctx, "INSERT INTO recipe_equipment (tenant_id, recipe_name, equipment_name) VALUES (?, ?, ?)", tenantID, recipeName, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEquipment ดึงอุปกรณ์ของ Recipe หลายรายการด้วย query เดียว โดยคืนค่าเป็น map ตามชื่อ Recipe
+func loadEquipment(ctx context.Context, q querier, tenantID string, recipeNames []string) (map[string][]string, error) {
+	equipment := make(map[string][]string)
+	if len(recipeNames) == 0 {
+		return equipment, nil
+	}
+
+	// ทุก Recipe ที่ขอมาจะได้รายการเสมอ แม้จะไม่มีอุปกรณ์เลยก็ตาม
+	args := []interface{}{tenantID}
+	for _, name := range recipeNames {
+		equipment[name] = []string{}
+		args = append(args, name)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(recipeNames)), ", ")
+
+	rows, err := q.QueryContext(ctx, "SELECT recipe_name, equipment_name FROM recipe_equipment WHERE tenant_id = ? AND recipe_name IN ("+placeholders+") ORDER BY equipment_name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipeName, name string
+		if err := rows.Scan(&recipeName, &name); err != nil {
+			return nil, err
+		}
+		equipment[recipeName] = append(equipment[recipeName], name)
+	}
+	return equipment, rows.Err()
+}
+
+// ListEquipment ดึงรายชื่ออุปกรณ์ที่ไม่ซ้ำกันพร้อมจำนวนสูตรอาหารที่ใช้
+func (m *MySQLStore) ListEquipment(ctx context.Context, tenantID string) (_ []EquipmentCount, err error) {
+	defer wrapStoreErr("ListEquipment", time.Now(), &err)
+
+	rows, err := m.db.QueryContext(ctx, "SELECT equipment_name, COUNT(*) FROM recipe_equipment WHERE tenant_id = ? GROUP BY equipment_name ORDER BY equipment_name", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	equipment := []EquipmentCount{}
+	for rows.Next() {
+		var item EquipmentCount
+		if err := rows.Scan(&item.Name, &item.Count); err != nil {
+			return nil, err
+		}
+		equipment = append(equipment, item)
+	}
+	return equipment, rows.Err()
+}
+
+// ListEquipment คือ handler สำหรับดึงรายชื่ออุปกรณ์พร้อมจำนวนการใช้งาน
+func (h *RecipesHandler) ListEquipment(c *gin.Context) {
+	equipment, err := h.store.ListEquipment(c.Request.Context(), tenantID(c))
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	RespondSuccess(c, http.StatusOK, equipment)
+}
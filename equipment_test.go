@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListRecipesByEquipment(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Sponge Cake","description":"d","equipment":["stand mixer","oven"]}`)
+	server.createRecipe(t, `{"name":"Steak","description":"d","equipment":["cast iron skillet"]}`)
+	server.createRecipe(t, `{"name":"Salad","description":"d","equipment":[]}`)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"/recipes?equipment=stand+mixer", []string{"Sponge Cake"}},
+		{"/recipes?equipment=cast%20iron%20skillet", []string{"Steak"}},
+		{"/recipes?equipment=Stand+Mixer", []string{"Sponge Cake"}},
+		{"/recipes?equipment=blender", []string{}},
+		{"/recipes", []string{"Sponge Cake", "Steak", "Salad"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			page := server.listRecipes(t, tc.query)
+			if got := recipeNames(page.Recipes); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("recipes = %v, want %v", got, tc.want)
+			}
+			if page.Total != len(tc.want) {
+				t.Errorf("total = %d, want %d", page.Total, len(tc.want))
+			}
+		})
+	}
+}
+
+func TestListEquipmentCounts(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Sponge Cake","description":"d","equipment":["stand mixer","oven"]}`)
+	server.createRecipe(t, `{"name":"Bread","description":"d","equipment":[" oven ","stand mixer","Oven",""]}`)
+
+	w := server.do(http.MethodGet, "/equipment", "")
+	expectStatus(t, w, http.StatusOK)
+	var equipment []EquipmentCount
+	decodeEnvelope(t, w, &equipment)
+
+	want := []EquipmentCount{{Name: "oven", Count: 2}, {Name: "stand mixer", Count: 2}}
+	if !reflect.DeepEqual(equipment, want) {
+		t.Errorf("equipment = %+v, want %+v", equipment, want)
+	}
+}
+
+func TestUpdateReplacesEquipment(t *testing.T) {
+	server := newTestServer(t, nil)
+	recipe := server.createRecipe(t, `{"name":"Steak","description":"d","equipment":["grill"]}`)
+
+	w := server.do(http.MethodPut, "/recipes/1", `{"name":"Steak","description":"d","equipment":["cast iron skillet"]}`, writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	decodeEnvelope(t, w, &recipe)
+	if !reflect.DeepEqual(recipe.Equipment, []string{"cast iron skillet"}) {
+		t.Errorf("equipment = %v", recipe.Equipment)
+	}
+	if page := server.listRecipes(t, "/recipes?equipment=grill"); len(page.Recipes) != 0 {
+		t.Errorf("recipes with old equipment = %v", recipeNames(page.Recipes))
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCodeInfo คือรายละเอียดของรหัส error หนึ่งรหัสใน catalog
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// errorCatalog คือรายการรหัส error ทั้งหมดที่ API ส่งได้ รหัสใหม่ต้องเพิ่มที่นี่ด้วย
+// เพื่อให้ client สร้าง enum จาก GET /meta/error-codes ได้ครบ
+var errorCatalog = []ErrorCodeInfo{
+	{Code: codeBadRequest, Status: http.StatusBadRequest, Description: "The request is malformed or has an invalid parameter."},
+	{Code: codeUnauthorized, Status: http.StatusUnauthorized, Description: "Credentials or the bearer token are missing or invalid."},
+	{Code: codeForbidden, Status: http.StatusForbidden, Description: "The caller's role does not allow this operation."},
+	{Code: codeNotFound, Status: http.StatusNotFound, Description: "The requested resource does not exist."},
+	{Code: codeConflict, Status: http.StatusConflict, Description: "The resource conflicts with an existing one, such as a duplicate name."},
+	{Code: codeLocked, Status: http.StatusLocked, Description: "The recipe is frozen by an administrator and cannot be changed."},
+	{Code: codeValidationFailed, Status: http.StatusUnprocessableEntity, Description: "A field failed validation; the field property names it."},
+	{Code: codeRequestCancelled, Status: statusClientClosedRequest, Description: "The request was cancelled by an administrator."},
+	{Code: codeTimeout, Status: http.StatusGatewayTimeout, Description: "The request did not finish within the server's time limit."},
+	{Code: codeReadOnly, Status: http.StatusServiceUnavailable, Description: "Writes are disabled because the database schema does not match this server version."},
+	{Code: codeUnavailable, Status: http.StatusServiceUnavailable, Description: "A dependency such as the database is unreachable, or ingredient routes are used before migration 0014 has run."},
+	{Code: codeInternalError, Status: http.StatusInternalServerError, Description: "An unexpected server error occurred."},
+}
+
+// ListErrorCodes คือ handler สำหรับดู catalog ของรหัส error
+func ListErrorCodes(c *gin.Context) {
+	RespondSuccess(c, http.StatusOK, errorCatalog)
+}
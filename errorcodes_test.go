@@ -0,0 +1,105 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListErrorCodes(t *testing.T) {
+	server := newTestServer(t, nil)
+	w := server.do(http.MethodGet, "/meta/error-codes", "")
+	expectStatus(t, w, http.StatusOK)
+
+	var catalog []ErrorCodeInfo
+	decodeEnvelope(t, w, &catalog)
+	if len(catalog) != len(errorCatalog) {
+		t.Fatalf("catalog has %d codes, want %d", len(catalog), len(errorCatalog))
+	}
+	seen := make(map[string]bool)
+	for _, info := range catalog {
+		if seen[info.Code] {
+			t.Errorf("code %q listed twice", info.Code)
+		}
+		seen[info.Code] = true
+		if info.Status < 400 || info.Description == "" {
+			t.Errorf("incomplete catalog entry %+v", info)
+		}
+	}
+}
+
+// parsePackage อ่านไฟล์ Go ที่ไม่ใช่ test ของ package นี้
+func parsePackage(t *testing.T) (*token.FileSet, []*ast.File) {
+	t.Helper()
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return fset, files
+}
+
+// TestErrorCodesAreCataloged ล้มเหลวถ้ามีรหัส error ที่ไม่อยู่ใน errorCatalog
+// handler ต้องส่งรหัสผ่านค่าคงที่ code* เท่านั้น จึงตรวจได้ครบจากค่าคงที่และจุดที่สร้าง error
+func TestErrorCodesAreCataloged(t *testing.T) {
+	cataloged := make(map[string]bool)
+	for _, info := range errorCatalog {
+		cataloged[info.Code] = true
+	}
+
+	fset, files := parsePackage(t)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ValueSpec:
+				// ค่าคงที่ code* ทุกตัวต้องอยู่ใน catalog
+				for i, name := range n.Names {
+					if !strings.HasPrefix(name.Name, "code") || i >= len(n.Values) {
+						continue
+					}
+					lit, ok := n.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					if code := strings.Trim(lit.Value, `"`); !cataloged[code] {
+						t.Errorf("%s: %s = %s is not in errorCatalog", fset.Position(name.Pos()), name.Name, lit.Value)
+					}
+				}
+			case *ast.CallExpr:
+				// respondErr(c, status, code, message)
+				if fn, ok := n.Fun.(*ast.Ident); ok && fn.Name == "respondErr" && len(n.Args) >= 3 {
+					expectCodeConstant(t, fset, n.Args[2])
+				}
+			case *ast.KeyValueExpr:
+				// AppError{Code: ...}
+				if key, ok := n.Key.(*ast.Ident); ok && key.Name == "Code" {
+					expectCodeConstant(t, fset, n.Value)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// expectCodeConstant ตรวจว่ารหัส error มาจากค่าคงที่ code* ไม่ใช่ string ที่เขียนตรง ๆ
+func expectCodeConstant(t *testing.T, fset *token.FileSet, expr ast.Expr) {
+	t.Helper()
+	if lit, ok := expr.(*ast.BasicLit); ok {
+		t.Errorf("%s: error code %s is a literal; use a code* constant from the catalog", fset.Position(lit.Pos()), lit.Value)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFetchNames คือจำนวนชื่อสูงสุดที่ POST /recipes/fetch รับได้ในครั้งเดียว
+const maxFetchNames = 50
+
+// GetMany ดึงข้อมูล Recipe หลายรายการตามชื่อด้วย query เดียว
+// map ที่คืนค่าใช้ชื่อตามที่ขอมาเป็น key ซึ่งอาจต่างจากชื่อที่เก็บไว้ เพราะ collation ของคอลัมน์ name
+// ไม่สนใจตัวพิมพ์ ชื่อที่ไม่พบจะไม่อยู่ใน map
+func (m *MySQLStore) GetMany(ctx context.Context, tenantID string, names []string) (_ map[string]Recipe, err error) {
+	defer wrapStoreErr("GetMany", time.Now(), &err)
+
+	recipes := make(map[string]Recipe)
+	if len(names) == 0 {
+		return recipes, nil
+	}
+
+	// ชื่อที่ขอมาแต่ละชื่อเป็นหนึ่งแถวของตาราง requested เพื่อให้ collation ของ recipe.name เป็นผู้ตัดสินการจับคู่
+	// และได้ชื่อตามที่ขอมากลับมาพร้อมกับแต่ละแถว
+	var args []interface{}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	args = append(args, tenantID)
+	requested := strings.TrimSuffix(strings.Repeat("SELECT ? AS requested_name UNION ALL ", len(names)), " UNION ALL ")
+
+	rows, err := m.db.QueryContext(ctx, "SELECT "+recipeColumns("description")+", requested.requested_name FROM recipe JOIN ("+requested+") AS requested ON recipe.name = requested.requested_name WHERE tenant_id = ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	storedNames := make(map[string]string)
+	var found []string
+	for rows.Next() {
+		var requestedName string
+		recipe, err := scanRecipe(extraColumnScanner{row: rows, extra: &requestedName})
+		if err != nil {
+			return nil, err
+		}
+		recipes[requestedName] = recipe
+		storedNames[requestedName] = recipe.Name
+		found = append(found, recipe.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	equipment, err := loadEquipment(ctx, m.db, tenantID, found)
+	if err != nil {
+		return nil, err
+	}
+	for requestedName, storedName := range storedNames {
+		recipe := recipes[requestedName]
+		recipe.Equipment = equipment[storedName]
+		recipes[requestedName] = recipe
+	}
+
+	return recipes, nil
+}
+
+// extraColumnScanner อ่านคอลัมน์ท้ายสุดที่ต่อจาก recipeColumns ลงใน extra
+type extraColumnScanner struct {
+	row   rowScanner
+	extra interface{}
+}
+
+func (s extraColumnScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra)...)
+}
+
+// fetchRecipesRequest คือ request body ของ POST /recipes/fetch
+type fetchRecipesRequest struct {
+	Names []string `json:"names"`
+}
+
+// FetchRecipesResponse คือผลลัพธ์ของ POST /recipes/fetch
+// ชื่อที่ไม่พบจะอยู่ใน Missing ตามลำดับที่ส่งเข้ามา
+type FetchRecipesResponse struct {
+	Recipes map[string]Recipe `json:"recipes"`
+	Missing []string          `json:"missing"`
+}
+
+// FetchRecipes คือ handler สำหรับดึงข้อมูลสูตรอาหารหลายรายการตามชื่อในครั้งเดียว
+func (h *RecipesHandler) FetchRecipes(c *gin.Context) {
+	// ดึง request body และแปลงเป็นรายชื่อที่ต้องการ
+	var request fetchRecipesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if len(request.Names) == 0 {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "names must contain at least one name")
+		return
+	}
+	if len(request.Names) > maxFetchNames {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("names must contain at most %d names", maxFetchNames))
+		return
+	}
+
+	recipes, err := h.store.GetMany(c.Request.Context(), tenantID(c), request.Names)
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	// ชื่อที่ไม่พบจะรายงานครั้งเดียวแม้จะส่งมาซ้ำ
+	missing := []string{}
+	seen := make(map[string]bool)
+	for _, name := range request.Names {
+		if _, ok := recipes[name]; ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		missing = append(missing, name)
+	}
+
+	RespondSuccess(c, http.StatusOK, FetchRecipesResponse{Recipes: recipes, Missing: missing})
+}
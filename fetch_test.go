@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFetchRecipesMixedNames(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pasta","description":"d","equipment":["pot"]}`)
+	server.createRecipe(t, `{"name":"Salad","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodPost, "/recipes/fetch", `{"names":["Pasta","soup","salad","soup"]}`)
+	expectStatus(t, w, http.StatusOK)
+	var response FetchRecipesResponse
+	decodeEnvelope(t, w, &response)
+
+	// ชื่อที่ต่างกันแค่ตัวพิมพ์พบได้เหมือน collation ของฐานข้อมูล และใช้ชื่อที่ขอมาเป็น key
+	keys := make([]string, 0, len(response.Recipes))
+	for name := range response.Recipes {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"Pasta", "salad"}) {
+		t.Errorf("recipes keys = %v, want [Pasta salad]", keys)
+	}
+	if got := response.Recipes["salad"].Name; got != "Salad" {
+		t.Errorf(`recipes["salad"].name = %q, want Salad`, got)
+	}
+	if got := response.Recipes["Pasta"].Equipment; !reflect.DeepEqual(got, []string{"pot"}) {
+		t.Errorf(`recipes["Pasta"].equipment = %v`, got)
+	}
+	if !reflect.DeepEqual(response.Missing, []string{"soup"}) {
+		t.Errorf("missing = %v, want [soup]", response.Missing)
+	}
+}
+
+func TestFetchRecipesAllFoundHasEmptyMissing(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pasta","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodPost, "/recipes/fetch", `{"names":["Pasta"]}`)
+	expectStatus(t, w, http.StatusOK)
+	if !strings.Contains(w.Body.String(), `"missing":[]`) {
+		t.Errorf("body = %s, want an empty missing list rather than null", w.Body.String())
+	}
+}
+
+func TestFetchRecipesLimits(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	names := make([]string, maxFetchNames+1)
+	for i := range names {
+		names[i] = "recipe"
+	}
+	tooMany, err := json.Marshal(fetchRecipesRequest{Names: names})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atLimit, err := json.Marshal(fetchRecipesRequest{Names: names[:maxFetchNames]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no names", `{"names":[]}`, http.StatusBadRequest},
+		{"malformed", `{"names":`, http.StatusBadRequest},
+		{"too many names", string(tooMany), http.StatusBadRequest},
+		{"at the limit", string(atLimit), http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectStatus(t, server.do(http.MethodPost, "/recipes/fetch", tc.body), tc.status)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFreezeReasonRunes คือความยาวสูงสุดของเหตุผลในการ freeze ตามขนาดคอลัมน์ frozen_reason
+const maxFreezeReasonRunes = 500
+
+// ErrFrozen คือ error เมื่อแก้ไขหรือลบ Recipe ที่ถูกล็อกโดยผู้ดูแลระบบ
+// error ที่คืนจาก store จะห่อ ErrFrozen ไว้พร้อมเหตุผล จึงต้องตรวจด้วย errors.Is
+var ErrFrozen = errors.New("recipe is frozen")
+
+// checkNotFrozen ล็อกแถวของ Recipe และตรวจสอบว่าไม่ได้ถูก freeze ไว้
+func checkNotFrozen(ctx context.Context, tx *sql.Tx, tenantID string, id int64) error {
+	var frozen bool
+	var reason sql.NullString
+	err := tx.QueryRowContext(ctx, "SELECT frozen, frozen_reason FROM recipe WHERE id = ? AND tenant_id = ? FOR UPDATE", id, tenantID).Scan(&frozen, &reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return recipeNotFound(id)
+	}
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return fmt.Errorf("%w: %s", ErrFrozen, reason.String)
+	}
+	return nil
+}
+
+// SetFrozen ล็อกหรือปลดล็อก Recipe โดย reason จะถูกเก็บไว้เฉพาะตอนล็อก
+func (m *MySQLStore) SetFrozen(ctx context.Context, tenantID string, id int64, frozen bool, reason string) (err error) {
+	defer wrapStoreErr("SetFrozen", time.Now(), &err)
+
+	if !frozen {
+		reason = ""
+	}
+
+	// UPDATE ครั้งเดียวจึงไม่มีช่องว่างระหว่างการตรวจกับการเขียน และ clientFoundRows ทำให้
+	// การล็อกซ้ำด้วยค่าเดิมยังนับเป็นแถวที่ตรงเงื่อนไข
+	result, err := m.db.ExecContext(ctx, "UPDATE recipe SET frozen = ?, frozen_reason = ? WHERE id = ? AND tenant_id = ?", frozen, nullString(reason), id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return recipeNotFound(id)
+	}
+	return nil
+}
+
+// freezeRequest คือ request body ของ POST /admin/recipes/:id/freeze
+type freezeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// FreezeRecipe คือ handler สำหรับล็อกสูตรอาหารไม่ให้แก้ไขหรือลบ
+func (h *RecipesHandler) FreezeRecipe(c *gin.Context) {
+	// ดึงพารามิเตอร์ URL
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	var request freezeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if request.Reason == "" {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "reason is required")
+		return
+	}
+	if utf8.RuneCountInString(request.Reason) > maxFreezeReasonRunes {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("reason must be at most %d characters", maxFreezeReasonRunes))
+		return
+	}
+
+	if err := h.store.SetFrozen(c.Request.Context(), tenantID(c), id, true, request.Reason); err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+
+	audit(c, "froze recipe %d: %s", id, request.Reason)
+	RespondSuccess(c, http.StatusOK, nil)
+}
+
+// UnfreezeRecipe คือ handler สำหรับปลดล็อกสูตรอาหาร
+func (h *RecipesHandler) UnfreezeRecipe(c *gin.Context) {
+	// ดึงพารามิเตอร์ URL
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.SetFrozen(c.Request.Context(), tenantID(c), id, false, ""); err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+
+	audit(c, "unfroze recipe %d", id)
+	RespondSuccess(c, http.StatusOK, nil)
+}
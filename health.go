@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// version คือเวอร์ชันของ build กำหนดตอน build ด้วย -ldflags "-X main.version=<version>"
+var version = "dev"
+
+// readinessTimeout คือเวลาสูงสุดที่ /readyz รอการตอบกลับจากฐานข้อมูล
+const readinessTimeout = 2 * time.Second
+
+// HealthChecker ตรวจสอบว่า dependency ของเซิร์ฟเวอร์พร้อมให้บริการ
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping ตรวจสอบการเชื่อมต่อกับฐานข้อมูล
+func (m *MySQLStore) Ping(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+// Ping ของ MemoryStore สำเร็จเสมอเพราะไม่มี dependency ภายนอก
+func (m *MemoryStore) Ping(_ context.Context) error {
+	return nil
+}
+
+// HealthHandler คือ handler ของ /healthz และ /readyz
+type HealthHandler struct {
+	checker   HealthChecker
+	startedAt time.Time
+}
+
+// NewHealthHandler สร้าง instance ใหม่ของ HealthHandler โดยเริ่มนับ uptime จากตอนนี้
+func NewHealthHandler(checker HealthChecker) *HealthHandler {
+	return &HealthHandler{checker: checker, startedAt: time.Now()}
+}
+
+// HealthStatus คือข้อมูลที่ /healthz และ /readyz ส่งกลับ
+type HealthStatus struct {
+	Status        string `json:"status"`
+	Version       string `json:"version"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// status สร้าง HealthStatus ของเวลาปัจจุบัน
+func (h *HealthHandler) status() HealthStatus {
+	return HealthStatus{
+		Status:        "ok",
+		Version:       version,
+		UptimeSeconds: int64(time.Since(h.startedAt).Seconds()),
+	}
+}
+
+// Liveness คือ handler ของ /healthz ตอบ 200 เสมอถ้า process ยังทำงานอยู่
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	RespondSuccess(c, http.StatusOK, h.status())
+}
+
+// Readiness คือ handler ของ /readyz ตอบ 503 ถ้าเชื่อมต่อฐานข้อมูลไม่ได้
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := h.checker.Ping(ctx); err != nil {
+		respondErr(c, http.StatusServiceUnavailable, codeUnavailable, "database: "+err.Error())
+		return
+	}
+	RespondSuccess(c, http.StatusOK, h.status())
+}
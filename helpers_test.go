@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ค่าที่ newTestServer ใช้ตั้งค่าเซิร์ฟเวอร์
+const (
+	testJWTSecret = "test-secret"
+	testAPIKey    = "test-api-key"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testEnvelope คือ Envelope ที่เก็บ data เป็น JSON ดิบเพื่อให้แต่ละ test แปลงเป็นชนิดที่ต้องการเอง
+type testEnvelope struct {
+	Data   json.RawMessage `json:"data"`
+	Meta   Meta            `json:"meta"`
+	Errors []AppError      `json:"errors"`
+}
+
+// performRequest ส่ง request ไปยัง handler และคืนผลลัพธ์ headers ระบุเป็นคู่ของชื่อและค่า
+func performRequest(handler http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// decodeEnvelope แปลง body ของ response เป็น testEnvelope และแปลง data เป็น data ถ้าไม่เป็น nil
+func decodeEnvelope(t *testing.T, w *httptest.ResponseRecorder, data interface{}) testEnvelope {
+	t.Helper()
+	var envelope testEnvelope
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode envelope: %v; body %s", err, w.Body.String())
+	}
+	if data != nil {
+		if err := json.Unmarshal(envelope.Data, data); err != nil {
+			t.Fatalf("decode data: %v; body %s", err, w.Body.String())
+		}
+	}
+	return envelope
+}
+
+// expectStatus ตรวจสอบ status code ของ response
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d; body %s", w.Code, want, w.Body.String())
+	}
+}
+
+// testServer คือเซิร์ฟเวอร์ที่สร้างด้วย newRouter บน MemoryStore
+type testServer struct {
+	router   *gin.Engine
+	store    recipeStore
+	users    userStore
+	registry *prometheus.Registry
+}
+
+// newTestServer สร้างเซิร์ฟเวอร์ที่มี route เหมือน main โดยใช้ MemoryStore
+// configure แก้ไข Config ก่อนสร้าง router ได้ ถ้าเป็น nil จะใช้ค่าเริ่มต้นของ test
+func newTestServer(t *testing.T, configure func(*Config)) *testServer {
+	t.Helper()
+	return newTestServerWithStore(t, NewMemoryStore(), configure)
+}
+
+// newTestServerWithStore สร้างเซิร์ฟเวอร์เหมือน newTestServer แต่ใช้ store ที่กำหนด
+func newTestServerWithStore(t *testing.T, store recipeStore, configure func(*Config)) *testServer {
+	t.Helper()
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StoreBackend = "memory"
+	cfg.JWTSecret = testJWTSecret
+	cfg.APIKeys = []string{testAPIKey}
+	cfg.APIKeysFile = ""
+	cfg.AuthDisabled = false
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	server := &testServer{
+		store:    store,
+		users:    NewMemoryUserStore(),
+		registry: prometheus.NewRegistry(),
+	}
+	server.router, err = newRouter(routerDeps{
+		Config:    cfg,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Registry:  server.registry,
+		Store:     server.store,
+		Users:     server.users,
+		Lifecycle: NewLifecycle(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+// do ส่ง request ไปยังเซิร์ฟเวอร์ ดู performRequest
+func (s *testServer) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	return performRequest(s.router, method, path, body, headers...)
+}
+
+// testToken ออก JWT ที่ลงนามด้วย testJWTSecret ให้ผู้ใช้ที่มี role ที่ระบุ
+func testToken(t *testing.T, role string) string {
+	t.Helper()
+	token, err := NewAuthHandler(nil, []byte(testJWTSecret)).issueToken(User{Username: role + "-user", TenantID: defaultTenantID, Role: role}, time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// writeHeaders คือ header ที่ใช้กับ route เขียนข้อมูลใน test ที่ไม่ได้ทดสอบการยืนยันตัวตน
+var writeHeaders = []string{apiKeyHeader, testAPIKey}
+
+// createRecipe เพิ่ม Recipe ผ่าน POST /recipes และคืน Recipe ที่บันทึกแล้ว
+func (s *testServer) createRecipe(t *testing.T, body string) Recipe {
+	t.Helper()
+	w := s.do(http.MethodPost, "/recipes", body, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	return recipe
+}
+
+// listRecipes ดึงรายการผ่าน GET path และคืนหน้าของรายการ
+func (s *testServer) listRecipes(t *testing.T, path string) RecipePage {
+	t.Helper()
+	w := s.do(http.MethodGet, path, "")
+	expectStatus(t, w, http.StatusOK)
+	var page RecipePage
+	decodeEnvelope(t, w, &page)
+	return page
+}
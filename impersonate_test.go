@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// impersonateResponse คือ data ของ POST /admin/impersonate
+type impersonateResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// captureAuditLog เปลี่ยน logger เริ่มต้นของ slog ให้เขียน JSON ลง buffer จนกว่า test จะจบ
+func captureAuditLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestImpersonation(t *testing.T) {
+	server := newTestServer(t, nil)
+	if err := server.users.AddUser(context.Background(), User{Username: "alice", Email: "alice@example.com", PasswordHash: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	adminToken := testToken(t, roleAdmin)
+
+	w := server.do(http.MethodPost, "/admin/impersonate", `{"user_id":"alice"}`, "Authorization", "Bearer "+adminToken)
+	expectStatus(t, w, http.StatusOK)
+	var response impersonateResponse
+	decodeEnvelope(t, w, &response)
+
+	claims, err := parseToken([]byte(testJWTSecret), response.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "alice" || claims.ImpersonatedBy != roleAdmin+"-user" {
+		t.Errorf("claims = sub %q impersonated_by %q", claims.Subject, claims.ImpersonatedBy)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > impersonationTTL || ttl < impersonationTTL-time.Minute {
+		t.Errorf("token expires in %s, want about %s", ttl, impersonationTTL)
+	}
+	if response.ExpiresIn != int(impersonationTTL.Seconds()) {
+		t.Errorf("expires_in = %d", response.ExpiresIn)
+	}
+
+	// token ที่สวมสิทธิ์ใช้ route ของผู้ใช้ได้ แต่ออก token สวมสิทธิ์ต่อไม่ได้
+	expectStatus(t, server.do(http.MethodGet, "/recipes", "", "Authorization", "Bearer "+response.Token), http.StatusOK)
+	expectStatus(t, server.do(http.MethodPost, "/admin/impersonate", `{"user_id":"alice"}`, "Authorization", "Bearer "+response.Token), http.StatusForbidden)
+
+	// แม้ผู้ใช้ที่ถูกสวมสิทธิ์จะเป็น admin ก็ออก token สวมสิทธิ์ต่อไม่ได้
+	impersonatedAdmin, err := NewAuthHandler(nil, []byte(testJWTSecret)).issueToken(User{Username: "bob", TenantID: defaultTenantID, Role: roleAdmin}, time.Minute, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = server.do(http.MethodPost, "/admin/impersonate", `{"user_id":"alice"}`, "Authorization", "Bearer "+impersonatedAdmin)
+	expectStatus(t, w, http.StatusForbidden)
+	if !strings.Contains(w.Body.String(), "impersonated tokens cannot impersonate") {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+func TestImpersonateErrors(t *testing.T) {
+	server := newTestServer(t, nil)
+	adminToken := testToken(t, roleAdmin)
+
+	cases := []struct {
+		name    string
+		body    string
+		headers []string
+		status  int
+	}{
+		{"unknown user", `{"user_id":"nobody"}`, []string{"Authorization", "Bearer " + adminToken}, http.StatusNotFound},
+		{"missing user_id", `{}`, []string{"Authorization", "Bearer " + adminToken}, http.StatusBadRequest},
+		{"not admin", `{"user_id":"alice"}`, []string{"Authorization", "Bearer " + testToken(t, roleEditor)}, http.StatusForbidden},
+		{"anonymous", `{"user_id":"alice"}`, nil, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectStatus(t, server.do(http.MethodPost, "/admin/impersonate", tc.body, tc.headers...), tc.status)
+		})
+	}
+}
+
+func TestAuditRecordsActorAndImpersonator(t *testing.T) {
+	logs := captureAuditLog(t)
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	token, err := NewAuthHandler(nil, []byte(testJWTSecret)).issueToken(User{Username: "alice", TenantID: defaultTenantID, Role: roleAdmin}, time.Minute, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := server.do(http.MethodPost, "/admin/recipes/1/freeze", `{"reason":"audit"}`, "Authorization", "Bearer "+token)
+	expectStatus(t, w, http.StatusOK)
+
+	var entry struct {
+		Msg            string `json:"msg"`
+		Actor          string `json:"actor"`
+		ImpersonatedBy string `json:"impersonated_by"`
+		Action         string `json:"action"`
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if err := json.Unmarshal([]byte(line), &entry); err == nil && entry.Msg == "audit" {
+			break
+		}
+	}
+	if entry.Msg != "audit" || entry.Actor != "alice" || entry.ImpersonatedBy != "root" || !strings.Contains(entry.Action, "froze recipe 1") {
+		t.Errorf("audit entry = %+v; log %s", entry, logs.String())
+	}
+}
+
+// tenantUserStore คือ userStore ที่มีผู้ใช้ใน tenant ที่กำหนด ซึ่ง MemoryUserStore สร้างไม่ได้เพราะใช้ tenant เริ่มต้นเสมอ
+type tenantUserStore struct {
+	userStore
+	users map[string]User
+}
+
+func (s tenantUserStore) GetUserByUsername(_ context.Context, username string) (User, error) {
+	if user, ok := s.users[username]; ok {
+		return user, nil
+	}
+	return User{}, &NotFoundError{Resource: "user", Key: username}
+}
+
+func TestImpersonateStaysWithinTenant(t *testing.T) {
+	secret := []byte(testJWTSecret)
+	handler := NewAuthHandler(tenantUserStore{users: map[string]User{
+		"alice": {Username: "alice", TenantID: "tenant-a", Role: "user"},
+		"bob":   {Username: "bob", TenantID: "tenant-b", Role: "user"},
+	}}, secret)
+	router := gin.New()
+	router.Use(TenantMiddleware(secret, APIKeys{}))
+	router.POST("/admin/impersonate", RequireAdmin(), handler.Impersonate)
+
+	adminToken, err := handler.issueToken(User{Username: "root", TenantID: "tenant-a", Role: roleAdmin}, time.Minute, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := []string{"Authorization", "Bearer " + adminToken}
+
+	w := performRequest(router, http.MethodPost, "/admin/impersonate", `{"user_id":"alice"}`, auth...)
+	expectStatus(t, w, http.StatusOK)
+
+	// ผู้ใช้ของ tenant อื่นตอบเหมือนผู้ใช้ที่ไม่มีอยู่
+	cross := performRequest(router, http.MethodPost, "/admin/impersonate", `{"user_id":"bob"}`, auth...)
+	expectStatus(t, cross, http.StatusNotFound)
+	missing := performRequest(router, http.MethodPost, "/admin/impersonate", `{"user_id":"carol"}`, auth...)
+	expectStatus(t, missing, http.StatusNotFound)
+	crossErrors, missingErrors := decodeEnvelope(t, cross, nil).Errors, decodeEnvelope(t, missing, nil).Errors
+	if len(crossErrors) != 1 || strings.Replace(crossErrors[0].Message, "bob", "carol", 1) != missingErrors[0].Message {
+		t.Errorf("cross-tenant errors %+v differ from unknown-user errors %+v", crossErrors, missingErrors)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest คือสถานะแบบ nginx 499 ที่ใช้ตอบ request ที่ถูกยกเลิก
+const statusClientClosedRequest = 499
+
+// inflightRequest คือข้อมูลของ request ที่กำลังทำงานอยู่
+// ID สร้างโดยเซิร์ฟเวอร์เสมอ ส่วน RequestID คือ X-Request-ID ที่ client ส่งมาได้ จึงอาจซ้ำกันและใช้อ้างอิงไม่ได้
+type inflightRequest struct {
+	ID        string    `json:"id"`
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	ClientIP  string    `json:"client_ip"`
+	StartedAt time.Time `json:"started_at"`
+	AgeMillis int64     `json:"age_ms"`
+
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+}
+
+// requestRegistry เก็บ request ที่กำลังทำงานอยู่ทั้งหมด
+// ใช้ sync.Map เพื่อให้การเพิ่มและลบใน hot path ไม่ต้องแย่ง lock เดียวกัน
+type requestRegistry struct {
+	entries sync.Map
+}
+
+// NewRequestRegistry สร้าง registry ใหม่
+func NewRequestRegistry() *requestRegistry {
+	return &requestRegistry{}
+}
+
+// Middleware ลงทะเบียน request ระหว่างที่ทำงานและลบออกเสมอเมื่อจบ แม้จะเกิด panic
+func (r *requestRegistry) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		entry := &inflightRequest{
+			ID:        randomID(),
+			RequestID: requestID(c),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			ClientIP:  c.ClientIP(),
+			StartedAt: time.Now(),
+			cancel:    cancel,
+		}
+		r.entries.Store(entry.ID, entry)
+		defer func() {
+			r.entries.Delete(entry.ID)
+			cancel()
+		}()
+
+		c.Next()
+
+		// request ที่ถูกยกเลิกโดย admin และยังไม่ได้ตอบกลับจะได้รับ 499
+		if entry.cancelled.Load() && !c.Writer.Written() {
+			respondErr(c, statusClientClosedRequest, codeRequestCancelled, "request cancelled by administrator")
+		}
+	}
+}
+
+// list คืนรายการ request ที่กำลังทำงาน เรียงจากที่ทำงานนานที่สุด
+func (r *requestRegistry) list() []*inflightRequest {
+	now := time.Now()
+	var requests []*inflightRequest
+	r.entries.Range(func(_, value interface{}) bool {
+		entry := value.(*inflightRequest)
+		requests = append(requests, &inflightRequest{
+			ID:        entry.ID,
+			RequestID: entry.RequestID,
+			Method:    entry.Method,
+			Route:     entry.Route,
+			Path:      entry.Path,
+			ClientIP:  entry.ClientIP,
+			StartedAt: entry.StartedAt,
+			AgeMillis: now.Sub(entry.StartedAt).Milliseconds(),
+		})
+		return true
+	})
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].StartedAt.Before(requests[j].StartedAt)
+	})
+	return requests
+}
+
+// cancel ยกเลิก context ของ request ที่มี id ตรงกัน id คือ ID ที่เซิร์ฟเวอร์สร้าง ไม่ใช่ X-Request-ID
+func (r *requestRegistry) cancel(id string) bool {
+	value, ok := r.entries.Load(id)
+	if !ok {
+		return false
+	}
+	entry := value.(*inflightRequest)
+	entry.cancelled.Store(true)
+	entry.cancel()
+	return true
+}
+
+// ListRequests คือ handler สำหรับดู request ที่กำลังทำงานอยู่
+func (r *requestRegistry) ListRequests(c *gin.Context) {
+	RespondSuccess(c, http.StatusOK, r.list())
+}
+
+// CancelRequest คือ handler สำหรับยกเลิก request ที่กำลังทำงานอยู่
+func (r *requestRegistry) CancelRequest(c *gin.Context) {
+	// ดึงพารามิเตอร์ URL
+	id := c.Param("id")
+
+	if !r.cancel(id) {
+		respondErr(c, http.StatusNotFound, codeNotFound, ErrNotFound.Error())
+		return
+	}
+
+	audit(c, "cancelled request %s", id)
+	RespondSuccess(c, http.StatusAccepted, nil)
+}
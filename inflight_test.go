@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// blockingStore คือ MemoryStore ที่ List รอจน context ของ request ถูกยกเลิก
+// และส่งสัญญาณใน started เมื่อเริ่มรอ
+type blockingStore struct {
+	recipeStore
+	started chan struct{}
+}
+
+func (s blockingStore) List(ctx context.Context, _ string, _ RecipeFilter, _ ListOptions) ([]Recipe, int, error) {
+	s.started <- struct{}{}
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+// listInflight ดึงรายการ request ที่กำลังทำงานผ่าน GET /admin/requests
+func listInflight(t *testing.T, server *testServer, adminToken string) []*inflightRequest {
+	t.Helper()
+	w := server.do(http.MethodGet, "/admin/requests", "", "Authorization", "Bearer "+adminToken)
+	expectStatus(t, w, http.StatusOK)
+	var requests []*inflightRequest
+	decodeEnvelope(t, w, &requests)
+	return requests
+}
+
+func TestAdminCancelsInflightRequest(t *testing.T) {
+	store := blockingStore{recipeStore: NewMemoryStore(), started: make(chan struct{})}
+	server := newTestServerWithStore(t, store, nil)
+	adminToken := testToken(t, roleAdmin)
+
+	// สอง request ใช้ X-Request-ID เดียวกัน แต่ต้องได้ ID ของ registry คนละตัว
+	results := make(chan *httptest.ResponseRecorder, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			results <- server.do(http.MethodGet, "/recipes", "", requestIDHeader, "same-id")
+		}()
+		<-store.started
+	}
+
+	// request ที่กำลังรอเรียงจากเก่าที่สุด และ GET /admin/requests เองอยู่ท้ายสุด
+	requests := listInflight(t, server, adminToken)
+	if len(requests) != 3 {
+		t.Fatalf("in-flight requests = %+v, want 2 blocked requests and the listing itself", requests)
+	}
+	if requests[0].ID == requests[1].ID {
+		t.Fatalf("requests with the same X-Request-ID share registry id %q", requests[0].ID)
+	}
+	for i, request := range requests[:2] {
+		if request.Route != "/recipes" || request.RequestID != "same-id" {
+			t.Errorf("requests[%d] = %+v", i, request)
+		}
+	}
+	if requests[2].Route != "/admin/requests" {
+		t.Errorf("requests[2] = %+v, want the listing request", requests[2])
+	}
+	if requests[0].StartedAt.After(requests[1].StartedAt) {
+		t.Errorf("requests are not sorted by age: %+v", requests)
+	}
+
+	for _, request := range requests[:2] {
+		w := server.do(http.MethodPost, "/admin/requests/"+request.ID+"/cancel", "", "Authorization", "Bearer "+adminToken)
+		expectStatus(t, w, http.StatusAccepted)
+		expectStatus(t, <-results, statusClientClosedRequest)
+	}
+
+	if remaining := listInflight(t, server, adminToken); len(remaining) != 1 {
+		t.Errorf("in-flight requests after cancel = %+v, want only the listing", remaining)
+	}
+	w := server.do(http.MethodPost, "/admin/requests/"+requests[0].ID+"/cancel", "", "Authorization", "Bearer "+adminToken)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestInflightEndpointsRequireAdmin(t *testing.T) {
+	server := newTestServer(t, nil)
+	cases := []struct {
+		name    string
+		headers []string
+		status  int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"editor", []string{"Authorization", "Bearer " + testToken(t, roleEditor)}, http.StatusForbidden},
+		{"api key", writeHeaders, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectStatus(t, server.do(http.MethodGet, "/admin/requests", "", tc.headers...), tc.status)
+			expectStatus(t, server.do(http.MethodPost, "/admin/requests/x/cancel", "", tc.headers...), tc.status)
+		})
+	}
+}
+
+func TestInflightEntryRemovedOnPanic(t *testing.T) {
+	registry := NewRequestRegistry()
+	router := gin.New()
+	router.Use(gin.Recovery(), registry.Middleware())
+	router.GET("/panic", func(*gin.Context) { panic("boom") })
+
+	expectStatus(t, performRequest(router, http.MethodGet, "/panic", ""), http.StatusInternalServerError)
+	if requests := registry.list(); len(requests) != 0 {
+		t.Errorf("registry after panic = %+v, want empty", requests)
+	}
+}
+
+func TestInflightRegistryConcurrentRequests(t *testing.T) {
+	registry := NewRequestRegistry()
+	router := gin.New()
+	router.Use(registry.Middleware())
+	router.GET("/work", func(c *gin.Context) {
+		registry.list()
+		c.Status(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			performRequest(router, http.MethodGet, "/work", "")
+			registry.cancel("unknown")
+		}()
+	}
+	wg.Wait()
+
+	if requests := registry.list(); len(requests) != 0 {
+		t.Errorf("registry after all requests finished = %+v, want empty", requests)
+	}
+}
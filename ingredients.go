@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wiratkhamphan/go-rest-demo/units"
+)
+
+// ingredientsMigration คือเลขของ migration ที่สร้างตาราง ingredient
+// MySQLStore ตรวจ version ก่อนใช้ตารางนี้ binary จึงยังทำงานกับ schema ก่อนหน้าได้ระหว่าง deploy
+const ingredientsMigration = 14
+
+// ErrIngredientsUnavailable คือ error เมื่อฐานข้อมูลยังไม่ได้รัน migration ที่สร้างตาราง ingredient
+var ErrIngredientsUnavailable = errors.New("ingredients are unavailable until migration 0014 has run")
+
+// Ingredient คือวัตถุดิบหนึ่งรายการของสูตรอาหาร
+type Ingredient struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// ingredientNotFound คืน NotFoundError ของวัตถุดิบตาม id
+func ingredientNotFound(id int64) error {
+	return &NotFoundError{Resource: "ingredient", Key: strconv.FormatInt(id, 10)}
+}
+
+// hasIngredients ตรวจสอบว่าฐานข้อมูลมีตาราง ingredient แล้วหรือยัง
+func (m *MySQLStore) hasIngredients() bool {
+	return m.schema >= ingredientsMigration
+}
+
+// AddIngredient เพิ่มวัตถุดิบให้ Recipe และคืนวัตถุดิบพร้อม id ที่ฐานข้อมูลสร้างให้
+// Recipe ที่ไม่มีอยู่ได้ NotFoundError และ Recipe ที่ถูก freeze จะแก้ไขวัตถุดิบไม่ได้
+func (m *MySQLStore) AddIngredient(ctx context.Context, tenantID string, recipeID int64, ingredient Ingredient) (_ Ingredient, err error) {
+	defer wrapStoreErr("AddIngredient", time.Now(), &err)
+	if !m.hasIngredients() {
+		return Ingredient{}, ErrIngredientsUnavailable
+	}
+
+	err = m.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkNotFrozen(ctx, tx, tenantID, recipeID); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, "INSERT INTO ingredient (tenant_id, recipe_id, name, quantity, unit) VALUES (?, ?, ?, ?, ?)",
+			tenantID, recipeID, ingredient.Name, ingredient.Quantity, ingredient.Unit)
+		if err != nil {
+			return err
+		}
+		ingredient.ID, err = result.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return Ingredient{}, err
+	}
+	return ingredient, nil
+}
+
+// ListIngredients ดึงวัตถุดิบของ Recipe เรียงตามลำดับที่เพิ่ม
+func (m *MySQLStore) ListIngredients(ctx context.Context, tenantID string, recipeID int64) (_ []Ingredient, err error) {
+	defer wrapStoreErr("ListIngredients", time.Now(), &err)
+	if !m.hasIngredients() {
+		return nil, ErrIngredientsUnavailable
+	}
+
+	var exists bool
+	err = m.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM recipe WHERE id = ? AND tenant_id = ?)", recipeID, tenantID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, recipeNotFound(recipeID)
+	}
+	return loadIngredients(ctx, m.db, tenantID, recipeID)
+}
+
+// loadIngredients ดึงวัตถุดิบของ Recipe โดยไม่ตรวจสอบว่า Recipe มีอยู่หรือไม่
+func loadIngredients(ctx context.Context, q querier, tenantID string, recipeID int64) ([]Ingredient, error) {
+	rows, err := q.QueryContext(ctx, "SELECT id, name, quantity, unit FROM ingredient WHERE recipe_id = ? AND tenant_id = ? ORDER BY id", recipeID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := []Ingredient{}
+	for rows.Next() {
+		var ingredient Ingredient
+		if err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Quantity, &ingredient.Unit); err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients, rows.Err()
+}
+
+// RemoveIngredient ลบวัตถุดิบออกจาก Recipe
+func (m *MySQLStore) RemoveIngredient(ctx context.Context, tenantID string, recipeID, ingredientID int64) (err error) {
+	defer wrapStoreErr("RemoveIngredient", time.Now(), &err)
+	if !m.hasIngredients() {
+		return ErrIngredientsUnavailable
+	}
+
+	return m.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkNotFrozen(ctx, tx, tenantID, recipeID); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, "DELETE FROM ingredient WHERE id = ? AND recipe_id = ? AND tenant_id = ?", ingredientID, recipeID, tenantID)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ingredientNotFound(ingredientID)
+		}
+		return nil
+	})
+}
+
+// IngredientsHandler คือ handler สำหรับวัตถุดิบของสูตรอาหาร
+type IngredientsHandler struct {
+	store recipeStore
+	units *units.Table
+}
+
+// NewIngredientsHandler สร้าง IngredientsHandler ใหม่ หน่วยของวัตถุดิบต้องอยู่ใน table
+func NewIngredientsHandler(store recipeStore, table *units.Table) *IngredientsHandler {
+	return &IngredientsHandler{store: store, units: table}
+}
+
+// validateIngredient ตัดช่องว่างของชื่อ แปลงหน่วยเป็นสัญลักษณ์มาตรฐาน แล้วตรวจสอบวัตถุดิบ
+// คืนรายละเอียดของทุกฟิลด์ที่ไม่ผ่าน
+func (h *IngredientsHandler) validateIngredient(ingredient *Ingredient) []AppError {
+	var details []AppError
+	ingredient.Name = strings.TrimSpace(ingredient.Name)
+	switch {
+	case ingredient.Name == "":
+		details = append(details, AppError{Code: codeValidationFailed, Field: "name", Message: "name is required"})
+	case utf8.RuneCountInString(ingredient.Name) > maxNameRunes:
+		details = append(details, AppError{Code: codeValidationFailed, Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxNameRunes)})
+	}
+	if ingredient.Quantity <= 0 {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "quantity", Message: "quantity must be positive"})
+	}
+	if unit, ok := h.units.Lookup(ingredient.Unit); ok {
+		ingredient.Unit = unit.Symbol
+	} else {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "unit", Message: "unit must be one of the units listed at /units"})
+	}
+	return details
+}
+
+// ingredientID อ่าน ingredientId จาก URL และตอบ 400 เองถ้าไม่ถูกต้อง
+func ingredientID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("ingredientId"), 10, 64)
+	if err != nil || id <= 0 {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "ingredientId must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// ListIngredients คือ handler สำหรับดึงวัตถุดิบของสูตรอาหาร
+func (h *IngredientsHandler) ListIngredients(c *gin.Context) {
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	ingredients, err := h.store.ListIngredients(c.Request.Context(), tenantID(c), id)
+	if err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+	RespondSuccess(c, http.StatusOK, ingredients)
+}
+
+// AddIngredient คือ handler สำหรับเพิ่มวัตถุดิบให้สูตรอาหาร
+func (h *IngredientsHandler) AddIngredient(c *gin.Context) {
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	var ingredient Ingredient
+	err := decodeStrict(c.Request.Body, &ingredient)
+	var unknown *unknownFieldError
+	if errors.As(err, &unknown) {
+		RespondError(c, http.StatusUnprocessableEntity, []AppError{{Code: codeValidationFailed, Field: unknown.Field, Message: "is not a known field"}})
+		return
+	}
+	if err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if details := h.validateIngredient(&ingredient); len(details) > 0 {
+		RespondError(c, http.StatusUnprocessableEntity, details)
+		return
+	}
+
+	ingredient, err = h.store.AddIngredient(c.Request.Context(), tenantID(c), id, ingredient)
+	if err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+	RespondSuccess(c, http.StatusCreated, ingredient)
+}
+
+// RemoveIngredient คือ handler สำหรับลบวัตถุดิบออกจากสูตรอาหาร
+func (h *IngredientsHandler) RemoveIngredient(c *gin.Context) {
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+	ingredient, ok := ingredientID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveIngredient(c.Request.Context(), tenantID(c), id, ingredient); err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+	RespondSuccess(c, http.StatusOK, nil)
+}
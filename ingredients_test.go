@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// addIngredient เพิ่มวัตถุดิบผ่าน POST /recipes/1/ingredients และคืนวัตถุดิบที่บันทึกแล้ว
+func (s *testServer) addIngredient(t *testing.T, body string) Ingredient {
+	t.Helper()
+	w := s.do(http.MethodPost, "/recipes/1/ingredients", body, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+	var ingredient Ingredient
+	decodeEnvelope(t, w, &ingredient)
+	return ingredient
+}
+
+func TestIngredientsEmbeddedInRecipe(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	noodles := server.addIngredient(t, `{"name":" rice noodles ","quantity":200,"unit":"grams"}`)
+	if noodles.ID == 0 || noodles.Name != "rice noodles" || noodles.Unit != "g" {
+		t.Errorf("added %+v, want a trimmed name and the canonical unit", noodles)
+	}
+	server.addIngredient(t, `{"name":"fish sauce","quantity":2,"unit":"tbsp"}`)
+
+	w := server.do(http.MethodGet, "/recipes/1", "")
+	expectStatus(t, w, http.StatusOK)
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if len(recipe.Ingredients) != 2 || recipe.Ingredients[0] != noodles || recipe.Ingredients[1].Name != "fish sauce" {
+		t.Errorf("embedded ingredients = %+v", recipe.Ingredients)
+	}
+
+	w = server.do(http.MethodGet, "/recipes/1/ingredients", "")
+	expectStatus(t, w, http.StatusOK)
+	var listed []Ingredient
+	decodeEnvelope(t, w, &listed)
+	if len(listed) != 2 {
+		t.Errorf("listed %+v", listed)
+	}
+}
+
+func TestIngredientsRequireExistingRecipe(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	cases := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/recipes/99/ingredients", `{"name":"egg","quantity":1,"unit":"g"}`},
+		{http.MethodGet, "/recipes/99/ingredients", ""},
+		{http.MethodDelete, "/recipes/99/ingredients/1", ""},
+		{http.MethodDelete, "/recipes/1/ingredients/99", ""},
+	}
+	for _, tc := range cases {
+		w := server.do(tc.method, tc.path, tc.body, writeHeaders...)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, w.Code, http.StatusNotFound)
+		}
+	}
+
+	// วัตถุดิบที่ถูกปฏิเสธต้องไม่ไปอยู่กับ Recipe อื่น
+	w := server.do(http.MethodGet, "/recipes/1/ingredients", "")
+	if !strings.Contains(w.Body.String(), `"data":[]`) {
+		t.Errorf("recipe 1 ingredients = %s, want none", w.Body.String())
+	}
+}
+
+func TestIngredientValidation(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	cases := []struct {
+		name, body string
+		fields     []string
+	}{
+		{"zero quantity", `{"name":"egg","quantity":0,"unit":"g"}`, []string{"quantity"}},
+		{"negative quantity", `{"name":"egg","quantity":-1,"unit":"g"}`, []string{"quantity"}},
+		{"unknown unit", `{"name":"egg","quantity":1,"unit":"bucket"}`, []string{"unit"}},
+		{"blank name", `{"name":" ","quantity":1,"unit":"g"}`, []string{"name"}},
+		{"every field", `{"name":"","quantity":0,"unit":""}`, []string{"name", "quantity", "unit"}},
+		{"unknown field", `{"name":"egg","quantity":1,"unit":"g","brand":"x"}`, []string{"brand"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(http.MethodPost, "/recipes/1/ingredients", tc.body, writeHeaders...)
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+			var fields []string
+			for _, e := range decodeEnvelope(t, w, nil).Errors {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tc.fields)
+			}
+		})
+	}
+}
+
+func TestRemoveIngredient(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	egg := server.addIngredient(t, `{"name":"egg","quantity":1,"unit":"g"}`)
+
+	path := "/recipes/1/ingredients/" + strconv.FormatInt(egg.ID, 10)
+	w := server.do(http.MethodDelete, path, "", writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	w = server.do(http.MethodDelete, path, "", writeHeaders...)
+	expectStatus(t, w, http.StatusNotFound)
+	w = server.do(http.MethodDelete, "/recipes/1/ingredients/abc", "", writeHeaders...)
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestDeleteRecipeCascadesToIngredients(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	server.addIngredient(t, `{"name":"egg","quantity":1,"unit":"g"}`)
+
+	w := server.do(http.MethodDelete, "/recipes/1", "", writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	w = server.do(http.MethodGet, "/recipes/1/ingredients", "")
+	expectStatus(t, w, http.StatusNotFound)
+
+	// Recipe ใหม่ที่ใช้ชื่อเดิมไม่ได้วัตถุดิบของ Recipe ที่ถูกลบไป
+	recreated := server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	w = server.do(http.MethodGet, "/recipes/"+strconv.FormatInt(recreated.ID, 10), "")
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if len(recipe.Ingredients) != 0 {
+		t.Errorf("recreated recipe has ingredients %+v", recipe.Ingredients)
+	}
+}
@@ -0,0 +1,316 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+)
+
+// test ใน integration_test.go ต้องใช้ Docker จึงรันเฉพาะเมื่อระบุ -tags=integration (ดู make test-integration)
+
+// ค่าของ MySQL container ที่ทุก test ในแพ็กเกจใช้ร่วมกัน
+const (
+	integrationImage    = "mysql:8.0.36"
+	integrationPassword = "test"
+)
+
+// integrationConfig คือ Config ที่ชี้ไปยัง container ซึ่งตั้งใน TestMain โดย DBName ว่างไว้ให้แต่ละ test กำหนดเอง
+var integrationConfig Config
+
+// integrationDB คือ connection ที่ไม่ได้เลือก database ใช้สร้างและลบ database ของแต่ละ test
+var integrationDB *sql.DB
+
+// schemaCounter ใช้ตั้งชื่อ database ของแต่ละ test ไม่ให้ซ้ำกัน
+var schemaCounter int64
+
+func TestMain(m *testing.M) {
+	os.Exit(runIntegration(m))
+}
+
+// runIntegration เริ่ม MySQL container หนึ่งตัวสำหรับทั้งแพ็กเกจ รัน test แล้วปิด container
+// แยกออกจาก TestMain เพื่อให้ defer ทำงานก่อน os.Exit
+func runIntegration(m *testing.M) int {
+	ctx := context.Background()
+
+	container, err := mysql.RunContainer(ctx,
+		testcontainers.WithImage(integrationImage),
+		mysql.WithUsername("root"),
+		mysql.WithPassword(integrationPassword),
+	)
+	if err != nil {
+		log.Printf("integration: start mysql container: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			log.Printf("integration: terminate mysql container: %v", err)
+		}
+	}()
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		log.Printf("integration: container host: %v", err)
+		return 1
+	}
+	port, err := container.MappedPort(ctx, "3306/tcp")
+	if err != nil {
+		log.Printf("integration: container port: %v", err)
+		return 1
+	}
+
+	integrationConfig = Config{
+		DBHost:          host,
+		DBPort:          port.Int(),
+		DBUser:          "root",
+		DBPassword:      integrationPassword,
+		MaxOpenConns:    10,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: time.Minute,
+	}
+	integrationDB, err = DBConnection(ctx, integrationConfig)
+	if err != nil {
+		log.Printf("integration: connect: %v", err)
+		return 1
+	}
+	defer integrationDB.Close()
+
+	return m.Run()
+}
+
+// newEmptyDatabase สร้าง database ว่างที่ยังไม่ได้รัน migration และคืนค่าตั้งที่ชี้ไปยัง database นั้น
+func newEmptyDatabase(t *testing.T) Config {
+	t.Helper()
+	name := "test_" + strconv.FormatInt(atomic.AddInt64(&schemaCounter, 1), 10)
+	if _, err := integrationDB.Exec("CREATE DATABASE " + name + " CHARACTER SET utf8mb4"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := integrationDB.Exec("DROP DATABASE " + name); err != nil {
+			t.Errorf("drop database %s: %v", name, err)
+		}
+	})
+	cfg := integrationConfig
+	cfg.DBName = name
+	return cfg
+}
+
+// connect เปิด connection pool ใหม่ตาม cfg และปิดเมื่อ test จบ
+func connect(t *testing.T, cfg Config) *sql.DB {
+	t.Helper()
+	db, err := DBConnection(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newTestDatabase สร้าง database ใหม่ที่รัน migration แล้วสำหรับ test หนึ่งตัว และลบทิ้งเมื่อ test จบ
+// แต่ละ test ได้ database ของตัวเองจึงรันขนานกันได้
+func newTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db := connect(t, newEmptyDatabase(t))
+	if err := Migrate(context.Background(), db, defaultMigrationLockTimeout); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// newMySQLTestStore สร้าง MySQLStore บน database ใหม่ที่ใช้ schema ล่าสุด
+func newMySQLTestStore(t *testing.T) recipeStore {
+	t.Helper()
+	db := newTestDatabase(t)
+	schema, err := CheckSchemaCompatibility(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMySQLStore(db, schema)
+}
+
+func TestMySQLStoreConformance(t *testing.T) {
+	testStoreConformance(t, newMySQLTestStore)
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+
+	// การรันซ้ำต้องไม่รัน migration ที่รันไปแล้วอีก
+	if err := Migrate(context.Background(), db, defaultMigrationLockTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("schema_migrations has %d rows, want %d", applied, len(migrations))
+	}
+}
+
+func TestMigrateStopsWhenContextIsCancelled(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Migrate(ctx, db, defaultMigrationLockTimeout); err == nil {
+		t.Fatal("Migrate with a cancelled context succeeded")
+	}
+}
+
+func TestDuplicateKeyFieldsMatchSchema(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+
+	// ทุก unique key ที่ duplicateField รู้จักต้องมีอยู่จริงใน schema
+	for key := range duplicateKeyFields {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND index_name = ?", key).Scan(&count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count == 0 {
+			t.Errorf("unique key %s does not exist", key)
+		}
+	}
+}
+
+func TestMySQLStoreErrorsAreWrapped(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+	store := NewMySQLStore(db, maxSchemaVersion)
+	db.Close()
+
+	_, err := store.Get(context.Background(), defaultTenantID, 1)
+	storeErr, ok := err.(*StoreError)
+	if !ok || storeErr.Op != "Get" {
+		t.Fatalf("err = %v (%T), want *StoreError for Get", err, err)
+	}
+}
+
+func TestIngredientsUnavailableBeforeMigration(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+
+	// schema ที่ยังไม่มีตาราง ingredient ยังอ่าน Recipe ได้ แต่การอ่านวัตถุดิบได้ ErrIngredientsUnavailable
+	store := NewMySQLStore(db, ingredientsMigration-1)
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+	if got := mustGet(t, store, id); got.Ingredients != nil {
+		t.Errorf("Ingredients = %+v, want none before migration %d", got.Ingredients, ingredientsMigration)
+	}
+	if _, err := store.ListIngredients(context.Background(), defaultTenantID, id); !errors.Is(err, ErrIngredientsUnavailable) {
+		t.Errorf("ListIngredients err = %v, want ErrIngredientsUnavailable", err)
+	}
+}
+
+func TestMySQLUserStore(t *testing.T) {
+	t.Parallel()
+	testUserStoreConformance(t, NewMySQLUserStore(newTestDatabase(t)))
+}
+
+// test ของ lock ไม่รันขนานกับ test อื่น เพราะ GET_LOCK ใช้ชื่อเดียวกันทั้งเซิร์ฟเวอร์
+
+func TestConcurrentMigrators(t *testing.T) {
+	cfg := newEmptyDatabase(t)
+
+	// จำลองสอง instance ที่เริ่มพร้อมกัน แต่ละตัวมี connection pool ของตัวเอง
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		db := connect(t, cfg)
+		go func() {
+			errs <- Migrate(context.Background(), db, defaultMigrationLockTimeout)
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("migrator %d: %v", i, err)
+		}
+	}
+
+	db := connect(t, cfg)
+	version, err := CheckSchemaCompatibility(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != maxSchemaVersion {
+		t.Errorf("schema version = %d, want %d", version, maxSchemaVersion)
+	}
+}
+
+func TestMigrateTimesOutWhileLockIsHeld(t *testing.T) {
+	cfg := newEmptyDatabase(t)
+	holder := connect(t, cfg)
+	conn, err := holder.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), "SELECT GET_LOCK(?, 0)", migrationLockName); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	start := time.Now()
+	err = Migrate(context.Background(), connect(t, cfg), time.Second)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want a lock timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Migrate waited %s for a 1s lock timeout", elapsed)
+	}
+}
+
+func TestMigrateRecoversFromStaleLock(t *testing.T) {
+	cfg := newEmptyDatabase(t)
+
+	// instance ที่ถือ lock ตายไประหว่าง migrate: connection ของมันถูกปิดโดยไม่ได้ RELEASE_LOCK
+	holder, err := DBConnection(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := holder.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "SELECT GET_LOCK(?, 0)", migrationLockName); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	holder.Close()
+
+	if err := Migrate(context.Background(), connect(t, cfg), 10*time.Second); err != nil {
+		t.Fatalf("Migrate after the lock holder died: %v", err)
+	}
+}
+
+func TestOldSchemaRefusesToStart(t *testing.T) {
+	t.Parallel()
+	db := newTestDatabase(t)
+
+	// ย้อน schema_migrations กลับไปเหมือนฐานข้อมูลที่ยังไม่ได้รัน 0013 ซึ่งเพิ่มคอลัมน์ updated_at
+	if _, err := db.Exec("DELETE FROM schema_migrations WHERE version >= ?", "0013"); err != nil {
+		t.Fatal(err)
+	}
+	version, err := CheckSchemaCompatibility(context.Background(), db)
+	if !errors.Is(err, ErrSchemaTooOld) || errors.Is(err, ErrSchemaIncompatible) {
+		t.Errorf("CheckSchemaCompatibility at %d = %v, want ErrSchemaTooOld so that startup fails", version, err)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonAPIMediaType คือ media type ของรูปแบบ JSON:API
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// jsonAPIRecipeType คือชื่อ type ของ Recipe ในรูปแบบ JSON:API
+const jsonAPIRecipeType = "recipes"
+
+// jsonAPIResource คือ resource object ของ JSON:API
+type jsonAPIResource struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Attributes Recipe            `json:"attributes"`
+	Links      map[string]string `json:"links,omitempty"`
+}
+
+// jsonAPIError คือ error object ของ JSON:API
+type jsonAPIError struct {
+	Status string            `json:"status"`
+	Code   string            `json:"code"`
+	Detail string            `json:"detail"`
+	Source map[string]string `json:"source,omitempty"`
+}
+
+// jsonAPIMeta คือ meta ของ document ที่มีข้อมูล Page มีเฉพาะใน collection ที่แบ่งหน้า
+type jsonAPIMeta struct {
+	Meta
+	Page *jsonAPIPage `json:"page,omitempty"`
+}
+
+// jsonAPIPage คือข้อมูลการแบ่งหน้าชุดเดียวกับ total, limit และ offset ของ RecipePage ในรูปแบบ JSON ปกติ
+type jsonAPIPage struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// jsonAPIDocument คือ top-level document ของ JSON:API ที่มีข้อมูล
+type jsonAPIDocument struct {
+	Data    interface{}       `json:"data"`
+	Meta    jsonAPIMeta       `json:"meta"`
+	Links   map[string]string `json:"links,omitempty"`
+	JSONAPI map[string]string `json:"jsonapi"`
+}
+
+// jsonAPIErrorDocument คือ top-level document ของ JSON:API ที่มี error
+type jsonAPIErrorDocument struct {
+	Errors  []jsonAPIError    `json:"errors"`
+	Meta    Meta              `json:"meta"`
+	JSONAPI map[string]string `json:"jsonapi"`
+}
+
+// jsonAPIVersion คือเวอร์ชันของ JSON:API ที่ใช้
+var jsonAPIVersion = map[string]string{"version": "1.1"}
+
+// wantsJSONAPI ตรวจสอบว่า client ขอ response ในรูปแบบ JSON:API หรือไม่
+func wantsJSONAPI(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), jsonAPIMediaType)
+}
+
+// respondJSONAPI ส่ง response สำเร็จในรูปแบบ JSON:API
+// Recipe และรายการ Recipe จะถูกแปลงเป็น resource object ส่วนข้อมูลอื่นจะส่งใน data ตามเดิม
+func respondJSONAPI(c *gin.Context, code int, data interface{}) {
+	doc := jsonAPIDocument{
+		Meta:    jsonAPIMeta{Meta: newMeta(c)},
+		Links:   map[string]string{"self": c.Request.URL.String()},
+		JSONAPI: jsonAPIVersion,
+	}
+
+	switch v := data.(type) {
+	case Recipe:
+		doc.Data = recipeResource(v)
+	case RecipePage:
+		resources := make([]jsonAPIResource, 0, len(v.Recipes))
+		for _, recipe := range v.Recipes {
+			resources = append(resources, recipeResource(recipe))
+		}
+		doc.Data = resources
+		doc.Meta.Page = &jsonAPIPage{Total: v.Total, Limit: v.Limit, Offset: v.Offset}
+		if v.Links.Next != "" {
+			doc.Links["next"] = v.Links.Next
+		}
+		if v.Links.Prev != "" {
+			doc.Links["prev"] = v.Links.Prev
+		}
+	case map[string]Recipe:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		resources := make([]jsonAPIResource, 0, len(v))
+		for _, name := range names {
+			resources = append(resources, recipeResource(v[name]))
+		}
+		doc.Data = resources
+	default:
+		doc.Data = data
+	}
+
+	c.Header("Content-Type", jsonAPIMediaType)
+	c.JSON(code, doc)
+}
+
+// respondJSONAPIError ส่ง error ในรูปแบบ errors array ของ JSON:API
+func respondJSONAPIError(c *gin.Context, code int, errs []AppError) {
+	doc := jsonAPIErrorDocument{
+		Meta:    newMeta(c),
+		JSONAPI: jsonAPIVersion,
+	}
+	for _, e := range errs {
+		apiErr := jsonAPIError{Status: strconv.Itoa(code), Code: e.Code, Detail: e.Message}
+		if e.Field != "" {
+			apiErr.Source = map[string]string{"pointer": "/data/attributes/" + e.Field}
+		}
+		doc.Errors = append(doc.Errors, apiErr)
+	}
+
+	c.Header("Content-Type", jsonAPIMediaType)
+	c.JSON(code, doc)
+}
+
+// recipeResource แปลง Recipe เป็น resource object
+// id ย้ายไปอยู่ระดับ resource เพราะ JSON:API ห้ามใช้ชื่อ id ใน attributes
+func recipeResource(recipe Recipe) jsonAPIResource {
+	id := strconv.FormatInt(recipe.ID, 10)
+	recipe.ID = 0
+	return jsonAPIResource{
+		Type:       jsonAPIRecipeType,
+		ID:         id,
+		Attributes: recipe,
+		Links:      map[string]string{"self": "/recipes/" + id},
+	}
+}
+
+// bindRecipe อ่าน Recipe หรือ RecipePatch จาก request body ทั้งแบบ JSON ปกติและแบบ JSON:API
+// แบบ JSON:API ใช้ data.attributes เป็นฟิลด์ของ recipe
+func bindRecipe(c *gin.Context, recipe interface{}) error {
+	if c.ContentType() != jsonAPIMediaType {
+		return decodeStrict(c.Request.Body, recipe)
+	}
+
+	var doc struct {
+		Data struct {
+			Type       string          `json:"type"`
+			ID         string          `json:"id"`
+			Attributes json.RawMessage `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		return err
+	}
+	if doc.Data.Type != jsonAPIRecipeType {
+		return errors.New(`data.type must be "recipes"`)
+	}
+
+	// id ของ resource มาจาก URL เสมอ จึงไม่ใช้ data.id ของ client
+	return decodeStrictBytes(doc.Data.Attributes, recipe)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testJSONAPIDocument คือ document ของ JSON:API ที่เก็บ data เป็น JSON ดิบ
+type testJSONAPIDocument struct {
+	Data    json.RawMessage   `json:"data"`
+	Errors  []jsonAPIError    `json:"errors"`
+	Meta    jsonAPIMeta       `json:"meta"`
+	Links   map[string]string `json:"links"`
+	JSONAPI map[string]string `json:"jsonapi"`
+}
+
+// jsonAPIHeaders ขอ response ในรูปแบบ JSON:API
+var jsonAPIHeaders = []string{"Accept", jsonAPIMediaType}
+
+// decodeJSONAPI ตรวจ media type และ member ระดับบนสุดที่ทุก document ต้องมี แล้วคืน document
+func decodeJSONAPI(t *testing.T, w *httptest.ResponseRecorder) testJSONAPIDocument {
+	t.Helper()
+	if got := w.Header().Get("Content-Type"); got != jsonAPIMediaType {
+		t.Errorf("Content-Type = %q, want %q", got, jsonAPIMediaType)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatalf("decode document: %v; body %s", err, w.Body.String())
+	}
+	// document ต้องมี data หรือ errors อย่างใดอย่างหนึ่งเท่านั้น
+	_, hasData := raw["data"]
+	_, hasErrors := raw["errors"]
+	if hasData == hasErrors {
+		t.Errorf("document has data=%v errors=%v, want exactly one; body %s", hasData, hasErrors, w.Body.String())
+	}
+
+	var doc testJSONAPIDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.JSONAPI["version"] != "1.1" {
+		t.Errorf("jsonapi = %v, want version 1.1", doc.JSONAPI)
+	}
+	if doc.Meta.RequestID == "" {
+		t.Error("meta.request_id is empty")
+	}
+	return doc
+}
+
+// expectRecipeResource ตรวจว่า resource object มี type id และ attributes ตาม JSON:API
+func expectRecipeResource(t *testing.T, data json.RawMessage, wantID, wantName string) {
+	t.Helper()
+	var resource struct {
+		Type       string                     `json:"type"`
+		ID         string                     `json:"id"`
+		Attributes map[string]json.RawMessage `json:"attributes"`
+		Links      map[string]string          `json:"links"`
+	}
+	if err := json.Unmarshal(data, &resource); err != nil {
+		t.Fatalf("decode resource: %v; %s", err, data)
+	}
+	if resource.Type != jsonAPIRecipeType || resource.ID != wantID {
+		t.Errorf("resource = %s/%s, want %s/%s", resource.Type, resource.ID, jsonAPIRecipeType, wantID)
+	}
+	// JSON:API ห้ามใช้ id และ type เป็นชื่อ attribute
+	for _, reserved := range []string{"id", "type"} {
+		if _, ok := resource.Attributes[reserved]; ok {
+			t.Errorf("attributes contain reserved member %q", reserved)
+		}
+	}
+	var name string
+	json.Unmarshal(resource.Attributes["name"], &name)
+	if name != wantName {
+		t.Errorf("attributes.name = %q, want %q", name, wantName)
+	}
+	if resource.Links["self"] != "/recipes/"+wantID {
+		t.Errorf("links.self = %q", resource.Links["self"])
+	}
+}
+
+func TestJSONAPISingleResource(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodGet, "/recipes/1", "", jsonAPIHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	doc := decodeJSONAPI(t, w)
+	expectRecipeResource(t, doc.Data, "1", "Pad Thai")
+	if doc.Links["self"] != "/recipes/1" {
+		t.Errorf("links.self = %q", doc.Links["self"])
+	}
+}
+
+func TestJSONAPICollection(t *testing.T) {
+	server := newTestServer(t, nil)
+	for _, name := range []string{"A", "B", "C"} {
+		server.createRecipe(t, `{"name":"`+name+`","description":"d","equipment":[]}`)
+	}
+
+	w := server.do(http.MethodGet, "/recipes?limit=2", "", jsonAPIHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	doc := decodeJSONAPI(t, w)
+	var resources []json.RawMessage
+	if err := json.Unmarshal(doc.Data, &resources); err != nil {
+		t.Fatalf("collection data is not an array: %s", doc.Data)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("got %d resources, want 2", len(resources))
+	}
+	expectRecipeResource(t, resources[0], "1", "A")
+	expectRecipeResource(t, resources[1], "2", "B")
+	if doc.Links["next"] == "" {
+		t.Error("links.next missing on a partial page")
+	}
+
+	// คอลเลกชันว่างยังเป็น array ไม่ใช่ null
+	w = server.do(http.MethodGet, "/recipes?q=nothing", "", jsonAPIHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	if doc := decodeJSONAPI(t, w); string(doc.Data) != "[]" {
+		t.Errorf("empty collection data = %s, want []", doc.Data)
+	}
+}
+
+func TestJSONAPIErrors(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	w := server.do(http.MethodGet, "/recipes/99", "", jsonAPIHeaders...)
+	expectStatus(t, w, http.StatusNotFound)
+	doc := decodeJSONAPI(t, w)
+	if len(doc.Errors) != 1 || doc.Errors[0].Status != "404" || doc.Errors[0].Code != codeNotFound {
+		t.Errorf("errors = %+v", doc.Errors)
+	}
+
+	// error ของฟิลด์ชี้ไปยัง attribute ด้วย source.pointer
+	headers := append([]string{"Content-Type", jsonAPIMediaType}, append(jsonAPIHeaders, writeHeaders...)...)
+	w = server.do(http.MethodPost, "/recipes", `{"data":{"type":"recipes","attributes":{"name":"","description":"d","equipment":[]}}}`, headers...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	doc = decodeJSONAPI(t, w)
+	if len(doc.Errors) == 0 || doc.Errors[0].Status != "422" || doc.Errors[0].Source["pointer"] != "/data/attributes/name" {
+		t.Errorf("errors = %+v", doc.Errors)
+	}
+}
+
+func TestJSONAPICreate(t *testing.T) {
+	server := newTestServer(t, nil)
+	headers := append([]string{"Content-Type", jsonAPIMediaType}, append(jsonAPIHeaders, writeHeaders...)...)
+
+	w := server.do(http.MethodPost, "/recipes", `{"data":{"type":"recipes","attributes":{"name":"Pad Thai","description":"d","equipment":[]}}}`, headers...)
+	expectStatus(t, w, http.StatusCreated)
+	expectRecipeResource(t, decodeJSONAPI(t, w).Data, "1", "Pad Thai")
+
+	// type อื่นที่ไม่ใช่ recipes ถูกปฏิเสธ
+	w = server.do(http.MethodPost, "/recipes", `{"data":{"type":"articles","attributes":{"name":"Other","description":"d","equipment":[]}}}`, headers...)
+	expectStatus(t, w, http.StatusBadRequest)
+	decodeJSONAPI(t, w)
+}
+
+func TestJSONAPIPatch(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":["wok"]}`)
+	headers := append([]string{"Content-Type", jsonAPIMediaType}, append(jsonAPIHeaders, writeHeaders...)...)
+
+	w := server.do(http.MethodPatch, "/recipes/1", `{"data":{"type":"recipes","id":"1","attributes":{"description":"stir-fried noodles"}}}`, headers...)
+	expectStatus(t, w, http.StatusOK)
+	doc := decodeJSONAPI(t, w)
+	expectRecipeResource(t, doc.Data, "1", "Pad Thai")
+
+	// ฟิลด์ที่ไม่ได้ส่งมาไม่เปลี่ยน
+	var recipe Recipe
+	decodeEnvelope(t, server.do(http.MethodGet, "/recipes/1", ""), &recipe)
+	if recipe.Description != "stir-fried noodles" || len(recipe.Equipment) != 1 {
+		t.Errorf("recipe after JSON:API PATCH = %+v", recipe)
+	}
+
+	// attribute ที่ไม่รู้จักและ type อื่นถูกปฏิเสธเหมือน POST
+	w = server.do(http.MethodPatch, "/recipes/1", `{"data":{"type":"recipes","attributes":{"calories":1}}}`, headers...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	if doc := decodeJSONAPI(t, w); len(doc.Errors) != 1 || doc.Errors[0].Source["pointer"] != "/data/attributes/calories" {
+		t.Errorf("errors = %+v", doc.Errors)
+	}
+	w = server.do(http.MethodPatch, "/recipes/1", `{"data":{"type":"articles","attributes":{"description":"x"}}}`, headers...)
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+// jsonAPIFixture คือ Recipe ที่ใช้ทุกฟิลด์ ใช้เทียบค่าระหว่าง JSON ปกติกับ JSON:API
+const jsonAPIFixture = `{"name":"ผัดไทย","description":"เส้นจันท์ผัดกับกุ้ง","source_url":"https://example.com/pad-thai","source_name":"Example","external_id":"12345","external_source":"spoonacular","equipment":["wok","spatula"]}`
+
+// plainAndJSONAPI ส่ง GET path ทั้งแบบ JSON ปกติและแบบ JSON:API ในนามของ admin ซึ่งเห็นทุกฟิลด์
+func plainAndJSONAPI(t *testing.T, server *testServer, path string) (testEnvelope, testJSONAPIDocument) {
+	t.Helper()
+	auth := []string{"Authorization", "Bearer " + testToken(t, roleAdmin)}
+	w := server.do(http.MethodGet, path, "", auth...)
+	expectStatus(t, w, http.StatusOK)
+	plain := decodeEnvelope(t, w, nil)
+	w = server.do(http.MethodGet, path, "", append(auth, jsonAPIHeaders...)...)
+	expectStatus(t, w, http.StatusOK)
+	return plain, decodeJSONAPI(t, w)
+}
+
+// expectSameAttributes ตรวจว่า attributes ของ resource ตรงกับ Recipe แบบ JSON ปกติทุกฟิลด์ ยกเว้น id ที่อยู่ระดับ resource
+func expectSameAttributes(t *testing.T, plain, resource json.RawMessage) {
+	t.Helper()
+	var want map[string]interface{}
+	if err := json.Unmarshal(plain, &want); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		ID         string                 `json:"id"`
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.Unmarshal(resource, &got); err != nil {
+		t.Fatal(err)
+	}
+	if id := want["id"]; fmt.Sprint(id) != got.ID {
+		t.Errorf("resource id = %q, plain id = %v", got.ID, id)
+	}
+	delete(want, "id")
+	if !reflect.DeepEqual(got.Attributes, want) {
+		t.Errorf("attributes = %v\nplain JSON = %v", got.Attributes, want)
+	}
+}
+
+func TestJSONAPIMatchesPlainJSON(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, jsonAPIFixture)
+	server.createRecipe(t, `{"name":"Tom Yum","description":"d","equipment":[]}`)
+	server.createRecipe(t, `{"name":"Larb","description":"d","equipment":["mortar"]}`)
+
+	plain, doc := plainAndJSONAPI(t, server, "/recipes/1")
+	expectSameAttributes(t, plain.Data, doc.Data)
+
+	plain, doc = plainAndJSONAPI(t, server, "/recipes?limit=1&offset=1&expand=description")
+	var page struct {
+		Recipes []json.RawMessage `json:"recipes"`
+		Total   int               `json:"total"`
+		Limit   int               `json:"limit"`
+		Offset  int               `json:"offset"`
+		Links   PageLinks         `json:"links"`
+	}
+	if err := json.Unmarshal(plain.Data, &page); err != nil {
+		t.Fatal(err)
+	}
+	var resources []json.RawMessage
+	if err := json.Unmarshal(doc.Data, &resources); err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != len(page.Recipes) {
+		t.Fatalf("JSON:API has %d resources, plain JSON has %d", len(resources), len(page.Recipes))
+	}
+	for i := range resources {
+		expectSameAttributes(t, page.Recipes[i], resources[i])
+	}
+
+	// ข้อมูลการแบ่งหน้าต้องตรงกันทั้งสองรูปแบบ
+	want := jsonAPIPage{Total: page.Total, Limit: page.Limit, Offset: page.Offset}
+	if want != (jsonAPIPage{Total: 3, Limit: 1, Offset: 1}) {
+		t.Errorf("plain JSON page = %+v", want)
+	}
+	if doc.Meta.Page == nil || *doc.Meta.Page != want {
+		t.Errorf("meta.page = %+v, plain JSON = %+v", doc.Meta.Page, want)
+	}
+	if doc.Links["next"] != page.Links.Next || doc.Links["prev"] != page.Links.Prev {
+		t.Errorf("links next %q prev %q, plain JSON next %q prev %q", doc.Links["next"], doc.Links["prev"], page.Links.Next, page.Links.Prev)
+	}
+
+	// resource เดี่ยวไม่มีข้อมูลการแบ่งหน้า
+	w := server.do(http.MethodGet, "/recipes/1", "", jsonAPIHeaders...)
+	if strings.Contains(w.Body.String(), `"page"`) {
+		t.Errorf("single resource has pagination meta: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultComponentTimeout คือเวลาสูงสุดที่ Start ของ component ใช้ได้ถ้าไม่ได้กำหนด Timeout
+const defaultComponentTimeout = 30 * time.Second
+
+// Component คือส่วนประกอบของเซิร์ฟเวอร์ที่ต้องเริ่มและหยุดตามลำดับ เช่นการเชื่อมต่อฐานข้อมูล
+type Component struct {
+	Name string
+
+	// ConfigSource คือตัวแปร environment ที่ใช้ตั้งค่า component เพื่อบอกในรายงานเมื่อเริ่มไม่สำเร็จ
+	ConfigSource string
+
+	// DependsOn คือชื่อของ component ที่ต้องเริ่มก่อน
+	DependsOn []string
+
+	// Timeout คือเวลาสูงสุดของ Start ถ้าเป็น 0 จะใช้ defaultComponentTimeout
+	Timeout time.Duration
+
+	Start func(ctx context.Context) error
+
+	// Stop และ Check ไม่บังคับ Check ใช้ตรวจสอบสถานะใน /healthz/details
+	Stop  func(ctx context.Context) error
+	Check func(ctx context.Context) error
+}
+
+// Lifecycle เริ่ม component ตามลำดับของ dependency และหยุดในลำดับย้อนกลับ
+type Lifecycle struct {
+	components []Component
+	started    []Component
+}
+
+// NewLifecycle สร้าง Lifecycle ที่ยังไม่มี component
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Register เพิ่ม component ลำดับการลงทะเบียนไม่สำคัญ เพราะ Start จะเรียงตาม DependsOn
+func (l *Lifecycle) Register(component Component) {
+	l.components = append(l.components, component)
+}
+
+// StartupError คือรายงานเมื่อ component เริ่มไม่สำเร็จ พร้อมผลการหยุด component ที่เริ่มไปแล้ว
+type StartupError struct {
+	Component    string
+	ConfigSource string
+	Err          error
+	StopErrors   []error
+}
+
+func (e *StartupError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "startup: component %q failed: %v", e.Component, e.Err)
+	if e.ConfigSource != "" {
+		fmt.Fprintf(&b, " (check %s)", e.ConfigSource)
+	}
+	for _, err := range e.StopErrors {
+		fmt.Fprintf(&b, "; while stopping: %v", err)
+	}
+	return b.String()
+}
+
+func (e *StartupError) Unwrap() error {
+	return e.Err
+}
+
+// order เรียง component ให้ dependency มาก่อนเสมอ และคืน error ถ้าอ้างถึงชื่อที่ไม่มีหรือมีวงจร
+func (l *Lifecycle) order() ([]Component, error) {
+	byName := make(map[string]Component, len(l.components))
+	for _, component := range l.components {
+		byName[component.Name] = component
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var ordered []Component
+	var visit func(name, from string) error
+	visit = func(name, from string) error {
+		component, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("startup: component %q depends on unknown component %q", from, name)
+		}
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("startup: dependency cycle through component %q", name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, dependency := range component.DependsOn {
+			if err := visit(dependency, name); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		ordered = append(ordered, component)
+		return nil
+	}
+
+	for _, component := range l.components {
+		if err := visit(component.Name, ""); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Start เริ่ม component ทั้งหมดตามลำดับ ถ้า component ใดล้มเหลวจะหยุด component ที่เริ่มไปแล้ว
+// ในลำดับย้อนกลับ และคืน *StartupError ที่บอกชื่อ component แหล่งค่าตั้ง และ error
+func (l *Lifecycle) Start(ctx context.Context) error {
+	ordered, err := l.order()
+	if err != nil {
+		return err
+	}
+
+	for _, component := range ordered {
+		if err := startComponent(ctx, component); err != nil {
+			return &StartupError{
+				Component:    component.Name,
+				ConfigSource: component.ConfigSource,
+				Err:          err,
+				StopErrors:   l.stop(ctx),
+			}
+		}
+		slog.Info("started component", slog.String("component", component.Name))
+		l.started = append(l.started, component)
+	}
+	return nil
+}
+
+// startComponent เรียก Start ของ component ด้วย ctx ที่หมดเวลาตาม Timeout
+// Start ต้องคืนค่าเมื่อ ctx ถูกยกเลิก เพื่อไม่ให้งานของ component ค้างอยู่ระหว่างที่ component ก่อนหน้าถูกหยุด
+func startComponent(ctx context.Context, component Component) error {
+	timeout := component.Timeout
+	if timeout == 0 {
+		timeout = defaultComponentTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := component.Start(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("did not start within %s: %w", timeout, err)
+	}
+	return err
+}
+
+// Stop หยุด component ที่เริ่มแล้วทั้งหมดในลำดับย้อนกลับ และคืน error ทั้งหมดที่เกิดขึ้นรวมกัน
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	return errors.Join(l.stop(ctx)...)
+}
+
+// stop หยุด component ที่เริ่มแล้วในลำดับย้อนกลับ และคืน error ของแต่ละ component
+func (l *Lifecycle) stop(ctx context.Context) []error {
+	var errs []error
+	for i := len(l.started) - 1; i >= 0; i-- {
+		component := l.started[i]
+		if component.Stop == nil {
+			continue
+		}
+		if err := component.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("component %q: %w", component.Name, err))
+		}
+	}
+	l.started = nil
+	return errs
+}
+
+// ComponentStatus คือสถานะของ component หนึ่งตัวใน /healthz/details
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Details คือ handler ของ /healthz/details ที่รัน Check ของทุก component ที่เริ่มแล้ว
+func (l *Lifecycle) Details(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	statuses := []ComponentStatus{}
+	for _, component := range l.started {
+		status := ComponentStatus{Name: component.Name, Status: "ok"}
+		if component.Check != nil {
+			if err := component.Check(ctx); err != nil {
+				status.Status, status.Error = "failing", sanitizeText(err.Error())
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	RespondSuccess(c, http.StatusOK, statuses)
+}
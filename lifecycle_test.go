@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingComponent สร้าง component ที่บันทึกการเริ่มและการหยุดลงใน events
+// startErr และ stopErr คือ error ที่ Start และ Stop คืน
+func recordingComponent(events *[]string, name string, dependsOn []string, startErr, stopErr error) Component {
+	return Component{
+		Name:         name,
+		ConfigSource: strings.ToUpper(name) + "_URL",
+		DependsOn:    dependsOn,
+		Start: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return stopErr
+		},
+	}
+}
+
+func TestLifecycleStartsInDependencyOrder(t *testing.T) {
+	var events []string
+	lifecycle := NewLifecycle()
+	// ลงทะเบียนกลับด้านกับลำดับที่ต้องเริ่ม
+	lifecycle.Register(recordingComponent(&events, "jobs", []string{"cache", "database"}, nil, nil))
+	lifecycle.Register(recordingComponent(&events, "cache", []string{"database"}, nil, nil))
+	lifecycle.Register(recordingComponent(&events, "database", nil, nil, nil))
+
+	if err := lifecycle.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := lifecycle.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "start database,start cache,start jobs,stop jobs,stop cache,stop database"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestLifecycleFailingMiddleComponent(t *testing.T) {
+	var events []string
+	errMigrate := errors.New("table is locked")
+	errClose := errors.New("close failed")
+	lifecycle := NewLifecycle()
+	lifecycle.Register(recordingComponent(&events, "database", nil, nil, errClose))
+	lifecycle.Register(recordingComponent(&events, "cache", []string{"database"}, nil, nil))
+	lifecycle.Register(recordingComponent(&events, "migrations", []string{"cache"}, errMigrate, nil))
+	lifecycle.Register(recordingComponent(&events, "jobs", []string{"migrations"}, nil, nil))
+
+	err := lifecycle.Start(context.Background())
+
+	// component ที่เริ่มแล้วถูกหยุดในลำดับย้อนกลับ ส่วน component หลังจากตัวที่ล้มเหลวไม่ถูกเริ่ม
+	want := "start database,start cache,start migrations,stop cache,stop database"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) {
+		t.Fatalf("err = %v, want *StartupError", err)
+	}
+	if startupErr.Component != "migrations" || startupErr.ConfigSource != "MIGRATIONS_URL" || !errors.Is(err, errMigrate) {
+		t.Errorf("report = %+v", startupErr)
+	}
+	if len(startupErr.StopErrors) != 1 || !errors.Is(startupErr.StopErrors[0], errClose) {
+		t.Errorf("StopErrors = %v, want the database close error", startupErr.StopErrors)
+	}
+	// รายงานบอกครบว่า component ใดล้มเหลว ตั้งค่าที่ใด และเกิดอะไรขึ้นระหว่างหยุด
+	for _, part := range []string{`"migrations"`, "table is locked", "MIGRATIONS_URL", `"database"`, "close failed"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q does not mention %s", err, part)
+		}
+	}
+
+	// Stop หลังจาก Start ล้มเหลวไม่หยุด component ซ้ำ
+	events = nil
+	if err := lifecycle.Stop(context.Background()); err != nil || len(events) != 0 {
+		t.Errorf("Stop after failed Start = %v, events %v", err, events)
+	}
+}
+
+func TestLifecycleStartTimeout(t *testing.T) {
+	var events []string
+	lifecycle := NewLifecycle()
+	lifecycle.Register(recordingComponent(&events, "database", nil, nil, nil))
+	lifecycle.Register(Component{
+		Name:      "migrations",
+		DependsOn: []string{"database"},
+		Timeout:   20 * time.Millisecond,
+		Start: func(ctx context.Context) error {
+			// เหมือน Migrate ที่รอ lock: คืนค่าเมื่อ ctx หมดเวลา
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	start := time.Now()
+	err := lifecycle.Start(context.Background())
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Start took %s with a 20ms timeout", elapsed)
+	}
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) || startupErr.Component != "migrations" {
+		t.Fatalf("err = %v, want a startup error for migrations", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "did not start within 20ms") {
+		t.Errorf("err = %v, want a start timeout", err)
+	}
+	if got := strings.Join(events, ","); got != "start database,stop database" {
+		t.Errorf("events = %s", got)
+	}
+}
+
+func TestLifecycleRejectsBadDependencies(t *testing.T) {
+	cases := []struct {
+		name       string
+		components []Component
+		want       string
+	}{
+		{"unknown", []Component{{Name: "cache", DependsOn: []string{"redis"}}}, `unknown component "redis"`},
+		{"cycle", []Component{
+			{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"a"}},
+		}, "dependency cycle"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lifecycle := NewLifecycle()
+			for _, component := range tc.components {
+				component.Start = func(ctx context.Context) error {
+					t.Error("Start called despite invalid dependencies")
+					return nil
+				}
+				lifecycle.Register(component)
+			}
+			if err := lifecycle.Start(context.Background()); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLifecycleDetails(t *testing.T) {
+	lifecycle := NewLifecycle()
+	lifecycle.Register(Component{Name: "database", Start: func(context.Context) error { return nil }, Check: func(context.Context) error { return nil }})
+	lifecycle.Register(Component{Name: "cache", Start: func(context.Context) error { return nil }, Check: func(context.Context) error { return errors.New("connection refused") }})
+	if err := lifecycle.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/healthz/details", lifecycle.Details)
+	w := performRequest(router, http.MethodGet, "/healthz/details", "")
+	expectStatus(t, w, http.StatusOK)
+	var statuses []ComponentStatus
+	decodeEnvelope(t, w, &statuses)
+	want := []ComponentStatus{
+		{Name: "database", Status: "ok"},
+		{Name: "cache", Status: "failing", Error: "connection refused"},
+	}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] {
+		t.Errorf("statuses = %+v, want %+v", statuses, want)
+	}
+}
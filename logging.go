@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// รูปแบบของ log ที่ LOG_FORMAT รองรับ
+const (
+	logFormatJSON = "json"
+	logFormatText = "text"
+)
+
+// parseLogLevel แปลงค่าของ LOG_LEVEL เช่น debug, info, warn, error เป็น slog.Level
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("config: LOG_LEVEL must be debug, info, warn or error, got %q", s)
+	}
+	return level, nil
+}
+
+// NewLogger สร้าง logger ตาม LogLevel และ LogFormat ของ cfg
+func NewLogger(w io.Writer, cfg Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.LogLevel}
+	if cfg.LogFormat == logFormatText {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// LoggingMiddleware บันทึก log หนึ่งบรรทัดต่อ request พร้อม request id เดียวกับที่ส่งกลับใน X-Request-ID
+// error ที่ handler บันทึกไว้ใน c.Errors (เช่น error จาก store ที่ตอบเป็น 5xx) จะอยู่ในบรรทัดเดียวกัน
+func LoggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := requestID(c)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", c.Request.Method),
+			slog.String("path", sanitizeLogText(c.Request.URL.Path)),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(c.Errors.Errors(), "; ")))
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
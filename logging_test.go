@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newLoggingRouter สร้าง router ที่มีเพียง LoggingMiddleware และคืน buffer ที่เก็บ log แบบ JSON
+func newLoggingRouter(t *testing.T) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(LoggingMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+	router.GET("/recipes/:id", func(c *gin.Context) {
+		RespondSuccess(c, http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/broken", func(c *gin.Context) {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, "store: connection refused")
+	})
+	return router, &buf
+}
+
+// decodeLogLine แปลง log บรรทัดเดียวใน buf เป็น map
+func decodeLogLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d log lines, want 1: %s", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v; %s", err, lines[0])
+	}
+	return entry
+}
+
+func TestLoggingMiddlewareLogsRequestFields(t *testing.T) {
+	router, buf := newLoggingRouter(t)
+	w := performRequest(router, http.MethodGet, "/recipes/7", "")
+	expectStatus(t, w, http.StatusOK)
+
+	id := w.Header().Get(requestIDHeader)
+	if !validRequestID.MatchString(id) {
+		t.Fatalf("X-Request-ID = %q, want a generated id", id)
+	}
+	entry := decodeLogLine(t, buf)
+	want := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "request",
+		"request_id": id,
+		"method":     "GET",
+		"path":       "/recipes/7",
+		"route":      "/recipes/:id",
+		"status":     float64(http.StatusOK),
+		"bytes":      float64(w.Body.Len()),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	for _, key := range []string{"latency_ms", "client_ip"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("log line has no %s: %v", key, entry)
+		}
+	}
+	if _, ok := entry["error"]; ok {
+		t.Errorf("successful request logged an error: %v", entry)
+	}
+
+	// request id ใน envelope ตรงกับ header และ log
+	if envelope := decodeEnvelope(t, w, nil); envelope.Meta.RequestID != id {
+		t.Errorf("meta.request_id = %q, want %q", envelope.Meta.RequestID, id)
+	}
+}
+
+func TestLoggingMiddlewareReusesRequestID(t *testing.T) {
+	cases := []struct {
+		name, header string
+		reused       bool
+	}{
+		{"valid", "req-123.abc_DEF", true},
+		{"invalid characters", "bad id\n", false},
+		{"too long", strings.Repeat("a", 65), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, buf := newLoggingRouter(t)
+			w := performRequest(router, http.MethodGet, "/recipes/1", "", requestIDHeader, tc.header)
+			id := w.Header().Get(requestIDHeader)
+			if (id == tc.header) != tc.reused {
+				t.Errorf("X-Request-ID = %q for incoming %q, reused want %v", id, tc.header, tc.reused)
+			}
+			if !validRequestID.MatchString(id) {
+				t.Errorf("X-Request-ID = %q is not a valid id", id)
+			}
+			if entry := decodeLogLine(t, buf); entry["request_id"] != id {
+				t.Errorf("logged request_id = %v, want %q", entry["request_id"], id)
+			}
+		})
+	}
+}
+
+func TestLoggingMiddlewareLogsServerErrors(t *testing.T) {
+	router, buf := newLoggingRouter(t)
+	w := performRequest(router, http.MethodGet, "/broken", "")
+	expectStatus(t, w, http.StatusInternalServerError)
+
+	// รายละเอียดของ error อยู่ใน log พร้อม request id แต่ไม่ถูกส่งให้ client
+	entry := decodeLogLine(t, buf)
+	if entry["level"] != "ERROR" || entry["error"] != "store: connection refused" {
+		t.Errorf("log = %v, want an ERROR line with the store error", entry)
+	}
+	if entry["request_id"] != w.Header().Get(requestIDHeader) {
+		t.Errorf("logged request_id = %v, header %q", entry["request_id"], w.Header().Get(requestIDHeader))
+	}
+	if strings.Contains(w.Body.String(), "connection refused") {
+		t.Errorf("response leaks the store error: %s", w.Body.String())
+	}
+}
+
+func TestNewLoggerHonoursConfig(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, Config{LogLevel: slog.LevelWarn, LogFormat: logFormatText})
+	logger.Info("hidden")
+	logger.Warn("shown")
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "level=WARN msg=shown") {
+		t.Errorf("text logger wrote %q", out)
+	}
+
+	buf.Reset()
+	NewLogger(&buf, Config{LogLevel: slog.LevelDebug, LogFormat: logFormatJSON}).Debug("shown")
+	if !json.Valid(bytes.TrimSpace(buf.Bytes())) {
+		t.Errorf("json logger wrote %q", buf.String())
+	}
+}
@@ -1,49 +1,133 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 // Recipe คือโครงสร้างที่แทนสูตรอาหาร
+//
+// ชื่อฟิลด์ JSON ใช้ snake_case ทั้งหมด ฟิลด์ข้อความที่ไม่บังคับใช้ omitempty (ไม่มีค่า = ไม่ส่ง)
+// ส่วนฟิลด์ที่เป็นรายการจะส่งเป็น [] เสมอเพื่อให้ client วนลูปได้โดยไม่ต้องตรวจ null
+//
+// ทุกฟิลด์ต้องมี tag visibility กำหนดระดับผู้ที่มองเห็นได้ (ดู redaction.go)
 type Recipe struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          int64  `json:"id,omitempty" visibility:"public"`
+	Name        string `json:"name" visibility:"public"`
+	Description string `json:"description" visibility:"public"`
+	SourceURL   string `json:"source_url,omitempty" visibility:"public"`
+	SourceName  string `json:"source_name,omitempty" visibility:"public"`
+
+	ExternalID     string `json:"external_id,omitempty" visibility:"staff"`
+	ExternalSource string `json:"external_source,omitempty" visibility:"staff"`
+
+	// Equipment คือรายการอุปกรณ์ที่ต้องใช้ทำสูตรอาหาร
+	Equipment []string `json:"equipment" visibility:"public"`
+
+	// Ingredients คือวัตถุดิบของสูตรอาหาร มีเฉพาะใน Get และแก้ไขผ่าน /recipes/:id/ingredients เท่านั้น
+	// ค่าที่ client ส่งมากับ Recipe จะถูกละเลย
+	Ingredients []Ingredient `json:"ingredients,omitempty" visibility:"public"`
+
+	// Metadata คือฟิลด์เพิ่มเติมตาม MetadataSchema ของ deployment
+	Metadata map[string]interface{} `json:"metadata,omitempty" visibility:"public"`
+
+	// CreatedAt และ UpdatedAt กำหนดโดย store เสมอ ค่าที่ client ส่งมาจะถูกละเลย
+	CreatedAt time.Time `json:"created_at" visibility:"public"`
+	UpdatedAt time.Time `json:"updated_at" visibility:"public"`
+
+	// Truncated บอกว่า Description ถูกตัดให้สั้นลงในรายการ
+	Truncated bool `json:"truncated,omitempty" visibility:"public"`
+}
+
+// RecipeFilter คือเงื่อนไขที่ใช้กรองรายการ Recipe
+type RecipeFilter struct {
+	// HasSource กรองเฉพาะ Recipe ที่มี source_url
+	HasSource bool
+
+	// SummaryLength ถ้ามากกว่า 0 จะดึง Description มาไม่เกิน SummaryLength+1 ตัวอักษร
+	// เพื่อไม่ต้องโหลดคำอธิบายขนาดใหญ่ทั้งหมดตอนแสดงรายการ
+	SummaryLength int
+
+	// Equipment กรองเฉพาะ Recipe ที่ต้องใช้อุปกรณ์ชื่อนี้
+	Equipment string
+
+	// Metadata กรองเฉพาะ Recipe ที่ฟิลด์ metadata มีค่าตรงกัน
+	Metadata map[string]string
+
+	// Query กรองเฉพาะ Recipe ที่ชื่อหรือคำอธิบายมีข้อความนี้ โดยไม่สนตัวพิมพ์เล็กใหญ่
+	Query string
 }
 
 // recipeStore คือ interface ที่กำหนดวิธีการจัดการกับข้อมูลของ Recipe
 type recipeStore interface {
-	Add(name string, recipe Recipe) error
-	Get(name string) (Recipe, error)
-	List() (map[string]Recipe, error)
-	Update(name string, recipe Recipe) error
-	Remove(name string) error
+	Add(ctx context.Context, tenantID string, recipe Recipe) (int64, error)
+	Get(ctx context.Context, tenantID string, id int64) (Recipe, error)
+	GetMany(ctx context.Context, tenantID string, names []string) (map[string]Recipe, error)
+	List(ctx context.Context, tenantID string, filter RecipeFilter, opts ListOptions) ([]Recipe, int, error)
+	Update(ctx context.Context, tenantID string, id int64, recipe Recipe) error
+	Remove(ctx context.Context, tenantID string, id int64) error
+	FindByExternalID(ctx context.Context, tenantID, externalID, externalSource string) (Recipe, error)
+	ListEquipment(ctx context.Context, tenantID string) ([]EquipmentCount, error)
+	PatchBatch(ctx context.Context, tenantID string, items []RecipePatchItem, continueOnError bool) ([]BatchItemResult, error)
+	SetFrozen(ctx context.Context, tenantID string, id int64, frozen bool, reason string) error
+	Patch(ctx context.Context, tenantID string, id int64, patch RecipePatch) (Recipe, error)
+	AddBatch(ctx context.Context, tenantID string, recipes []Recipe) ([]int64, error)
+	AddIngredient(ctx context.Context, tenantID string, recipeID int64, ingredient Ingredient) (Ingredient, error)
+	ListIngredients(ctx context.Context, tenantID string, recipeID int64) ([]Ingredient, error)
+	RemoveIngredient(ctx context.Context, tenantID string, recipeID, ingredientID int64) error
+	HealthChecker
 }
 
 // MySQLStore เป็น implement ของ recipeStore ที่ใช้ MySQL
 type MySQLStore struct {
 	db *sql.DB
+
+	// schema คือ version ของ schema ที่อ่านได้ตอนเริ่มเซิร์ฟเวอร์ ใช้ตัดสินว่ามีตารางที่เพิ่มทีหลังหรือยัง
+	schema int
 }
 
-// DBConnection ทำการเชื่อมต่อกับฐานข้อมูล MySQL
-func DBConnection() (*sql.DB, error) {
-	dbDriver := "mysql"
-	dbUser := "root"
-	dbPass := ""
-	dbName := "web_lek"
+// DBConnection ทำการเชื่อมต่อกับฐานข้อมูล MySQL ตาม Config
+func DBConnection(ctx context.Context, cfg Config) (*sql.DB, error) {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.DBUser
+	dsn.Passwd = cfg.DBPassword
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(cfg.DBHost, strconv.Itoa(cfg.DBPort))
+	dsn.DBName = cfg.DBName
+
+	// clientFoundRows ทำให้ RowsAffected นับแถวที่ตรงเงื่อนไข แม้ค่าจะไม่เปลี่ยน
+	// ไม่อย่างนั้นการ UPDATE ด้วยค่าเดิมจะได้ 0 แถวและถูกตีความเป็น ErrNotFound
+	dsn.ClientFoundRows = true
+
+	// อ่าน TIMESTAMP เป็น time.Time ใน UTC
+	dsn.ParseTime = true
 
-	db, err := sql.Open(dbDriver, dbUser+":"+dbPass+"@/"+dbName)
+	db, err := sql.Open("mysql", dsn.FormatDSN())
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// ทดสอบการเชื่อมต่อ
-	err = db.Ping()
-	if err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -51,71 +135,243 @@ func DBConnection() (*sql.DB, error) {
 }
 
 // NewMySQLStore สร้าง instance ใหม่ของ MySQL store
-func NewMySQLStore(db *sql.DB) recipeStore {
-	return &MySQLStore{db: db}
+// schema คือ version ที่ CheckSchemaCompatibility คืนค่า
+func NewMySQLStore(db *sql.DB, schema int) recipeStore {
+	return &MySQLStore{db: db, schema: schema}
 }
 
 // นิยาม method ของ interface recipeStore สำหรับ MySQLStore
 
-// Add เพิ่ม Recipe เข้าสู่ฐานข้อมูล
-func (m *MySQLStore) Add(name string, recipe Recipe) error {
-	_, err := m.db.Exec("INSERT INTO recipe (name, description) VALUES (?, ?)", name, recipe.Description)
-	return err
+// recipeColumns คืนรายชื่อคอลัมน์ที่ใช้ SELECT ข้อมูล Recipe ตามลำดับของ scanRecipe
+// โดยใช้ description เป็น expression ของคอลัมน์คำอธิบาย
+func recipeColumns(description string) string {
+	return "id, name, " + description + ", source_url, source_name, external_id, external_source, metadata, created_at, updated_at"
+}
+
+// querier คือ interface ที่ทั้ง *sql.DB และ *sql.Tx implement
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
-// Get ดึงข้อมูล Recipe จากฐานข้อมูล
-func (m *MySQLStore) Get(name string) (Recipe, error) {
+// rowScanner คือ interface ที่ทั้ง *sql.Row และ *sql.Rows implement
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRecipe อ่านข้อมูล Recipe หนึ่งแถวตามลำดับของ recipeColumns
+func scanRecipe(row rowScanner) (Recipe, error) {
 	var recipe Recipe
-	err := m.db.QueryRow("SELECT name, description FROM recipe WHERE name = ?", name).Scan(&recipe.Name, &recipe.Description)
+	var sourceURL, sourceName, externalID, externalSource, metadata sql.NullString
+	err := row.Scan(&recipe.ID, &recipe.Name, &recipe.Description, &sourceURL, &sourceName, &externalID, &externalSource, &metadata, &recipe.CreatedAt, &recipe.UpdatedAt)
 	if err != nil {
-		return Recipe{}, ErrNotFound
+		return Recipe{}, err
+	}
+	if recipe.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return Recipe{}, err
 	}
+	recipe.SourceURL = sourceURL.String
+	recipe.SourceName = sourceName.String
+	recipe.ExternalID = externalID.String
+	recipe.ExternalSource = externalSource.String
 	return recipe, nil
 }
 
-// List ดึงรายการ Recipe ทั้งหมดจากฐานข้อมูล
-func (m *MySQLStore) List() (map[string]Recipe, error) {
-	rows, err := m.db.Query("SELECT name, description FROM recipe")
+// nullString แปลงสตริงว่างเป็น NULL สำหรับคอลัมน์ที่ไม่บังคับ
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// withTx เรียก fn ภายใน transaction และ commit เมื่อ fn สำเร็จ หรือ rollback เมื่อเกิด error
+func (m *MySQLStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Add เพิ่ม Recipe และอุปกรณ์ที่ใช้เข้าสู่ฐานข้อมูลภายใน transaction เดียว แล้วคืน id ที่ฐานข้อมูลสร้างให้
+func (m *MySQLStore) Add(ctx context.Context, tenantID string, recipe Recipe) (_ int64, err error) {
+	defer wrapStoreErr("Add", time.Now(), &err)
+
+	var id int64
+	err = m.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = addRecipeTx(ctx, tx, tenantID, recipe)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// addRecipeTx เพิ่ม Recipe และอุปกรณ์ภายใน transaction ที่ส่งเข้ามา และคืน id ที่สร้างให้
+func addRecipeTx(ctx context.Context, tx *sql.Tx, tenantID string, recipe Recipe) (int64, error) {
+	metadata, err := marshalMetadata(recipe.Metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO recipe (tenant_id, name, description, source_url, source_name, external_id, external_source, metadata) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		tenantID, recipe.Name, recipe.Description, nullString(recipe.SourceURL), nullString(recipe.SourceName),
+		nullString(recipe.ExternalID), nullString(recipe.ExternalSource), metadata)
+	if isDuplicateKey(err) {
+		return 0, recipeConflict(recipe, duplicateField(err, "name"))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, insertEquipment(ctx, tx, tenantID, recipe.Name, recipe.Equipment)
+}
+
+// Get ดึงข้อมูล Recipe และอุปกรณ์ที่ใช้จากฐานข้อมูล
+func (m *MySQLStore) Get(ctx context.Context, tenantID string, id int64) (_ Recipe, err error) {
+	defer wrapStoreErr("Get", time.Now(), &err)
+
+	return m.loadRecipe(ctx, m.db, tenantID, id)
+}
+
+// loadRecipe ดึง Recipe พร้อมอุปกรณ์และวัตถุดิบด้วย q ซึ่งเป็นได้ทั้ง *sql.DB และ *sql.Tx
+// ถ้าฐานข้อมูลยังไม่มีตาราง ingredient จะคืน Recipe โดยไม่มีวัตถุดิบ
+func (m *MySQLStore) loadRecipe(ctx context.Context, q querier, tenantID string, id int64) (Recipe, error) {
+	recipe, err := scanRecipe(q.QueryRowContext(ctx, "SELECT "+recipeColumns("description")+" FROM recipe WHERE id = ? AND tenant_id = ?", id, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Recipe{}, recipeNotFound(id)
+	}
+	if err != nil {
+		return Recipe{}, err
+	}
+
+	equipment, err := loadEquipment(ctx, q, tenantID, []string{recipe.Name})
+	if err != nil {
+		return Recipe{}, err
+	}
+	recipe.Equipment = equipment[recipe.Name]
+
+	if !m.hasIngredients() {
+		return recipe, nil
+	}
+	recipe.Ingredients, err = loadIngredients(ctx, q, tenantID, id)
+	if err != nil {
+		return Recipe{}, err
+	}
+	return recipe, nil
+}
+
+// List ดึงรายการ Recipe หนึ่งหน้าตามเงื่อนไขและลำดับที่กำหนด พร้อมจำนวนทั้งหมดที่ตรงกับเงื่อนไข
+func (m *MySQLStore) List(ctx context.Context, tenantID string, filter RecipeFilter, opts ListOptions) (_ []Recipe, _ int, err error) {
+	defer wrapStoreErr("List", time.Now(), &err)
+
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{tenantID}
+	if filter.HasSource {
+		conditions = append(conditions, "source_url IS NOT NULL")
+	}
+	if filter.Equipment != "" {
+		conditions = append(conditions, "name IN (SELECT recipe_name FROM recipe_equipment WHERE tenant_id = ? AND equipment_name = ?)")
+		args = append(args, tenantID, filter.Equipment)
+	}
+	if filter.Query != "" {
+		conditions = append(conditions, "(LOWER(name) LIKE ? ESCAPE '"+likeEscape+"' OR LOWER(description) LIKE ? ESCAPE '"+likeEscape+"')")
+		pattern := likePattern(filter.Query)
+		args = append(args, pattern, pattern)
+	}
+	fields := make([]string, 0, len(filter.Metadata))
+	for name := range filter.Metadata {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	for _, name := range fields {
+		conditions = append(conditions, "JSON_UNQUOTE(JSON_EXTRACT(metadata, ?)) = ?")
+		args = append(args, metadataPath(name), filter.Metadata[name])
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM recipe"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	description := "description"
+	if filter.SummaryLength > 0 {
+		description = "LEFT(description, " + strconv.Itoa(filter.SummaryLength+1) + ")"
+	}
+
+	query := "SELECT " + recipeColumns(description) + " FROM recipe" + where + opts.orderBy() + " LIMIT ? OFFSET ?"
+
+	rows, err := m.db.QueryContext(ctx, query, append(args, opts.Limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	recipes := make(map[string]Recipe)
+	recipes := []Recipe{}
+	var names []string
 	for rows.Next() {
-		var recipe Recipe
-		err := rows.Scan(&recipe.Name, &recipe.Description)
+		recipe, err := scanRecipe(rows)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
-		recipes[recipe.Name] = recipe
+		recipes = append(recipes, recipe)
+		names = append(names, recipe.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
 	}
 
-	return recipes, nil
+	// ดึงอุปกรณ์ของทุก Recipe ในหน้าด้วย query เดียว
+	equipment, err := loadEquipment(ctx, m.db, tenantID, names)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range recipes {
+		recipes[i].Equipment = equipment[recipes[i].Name]
+	}
+
+	return recipes, total, nil
 }
 
-// Update อัพเดตข้อมูล Recipe ในฐานข้อมูล
-func (m *MySQLStore) Update(name string, recipe Recipe) error {
-	result, err := m.db.Exec("UPDATE recipe SET description = ? WHERE name = ?", recipe.Description, name)
-	if err != nil {
+// Update อัพเดตข้อมูล Recipe รวมถึงชื่อ และแทนที่รายการอุปกรณ์ในฐานข้อมูลภายใน transaction เดียว
+// Recipe ที่ถูก freeze จะแก้ไขไม่ได้
+func (m *MySQLStore) Update(ctx context.Context, tenantID string, id int64, recipe Recipe) (err error) {
+	defer wrapStoreErr("Update", time.Now(), &err)
+
+	return m.withTx(ctx, func(tx *sql.Tx) error {
+		return updateRecipeTx(ctx, tx, tenantID, id, recipe)
+	})
+}
+
+// updateRecipeTx อัพเดตข้อมูล Recipe และแทนที่รายการอุปกรณ์ภายใน transaction ที่ส่งเข้ามา
+// การเปลี่ยนชื่อจะเปลี่ยน recipe_name ของอุปกรณ์ตามไปด้วยผ่าน ON UPDATE CASCADE
+func updateRecipeTx(ctx context.Context, tx *sql.Tx, tenantID string, id int64, recipe Recipe) error {
+	if err := checkNotFrozen(ctx, tx, tenantID, id); err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	metadata, err := marshalMetadata(recipe.Metadata)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return ErrNotFound
+	result, err := tx.ExecContext(ctx, "UPDATE recipe SET name = ?, description = ?, source_url = ?, source_name = ?, external_id = ?, external_source = ?, metadata = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND tenant_id = ?",
+		recipe.Name, recipe.Description, nullString(recipe.SourceURL), nullString(recipe.SourceName),
+		nullString(recipe.ExternalID), nullString(recipe.ExternalSource), metadata, id, tenantID)
+	if isDuplicateKey(err) {
+		return recipeConflict(recipe, duplicateField(err, "name"))
 	}
-
-	return nil
-}
-
-// Remove ลบ Recipe จากฐานข้อมูล
-func (m *MySQLStore) Remove(name string) error {
-	result, err := m.db.Exec("DELETE FROM recipe WHERE name = ?", name)
 	if err != nil {
 		return err
 	}
@@ -126,152 +382,388 @@ func (m *MySQLStore) Remove(name string) error {
 	}
 
 	if rowsAffected == 0 {
-		return ErrNotFound
+		return recipeNotFound(id)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM recipe_equipment WHERE tenant_id = ? AND recipe_name = ?", tenantID, recipe.Name)
+	if err != nil {
+		return err
 	}
+	return insertEquipment(ctx, tx, tenantID, recipe.Name, recipe.Equipment)
+}
+
+// Remove ลบ Recipe จากฐานข้อมูล Recipe ที่ถูก freeze จะลบไม่ได้
+func (m *MySQLStore) Remove(ctx context.Context, tenantID string, id int64) (err error) {
+	defer wrapStoreErr("Remove", time.Now(), &err)
+
+	return m.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkNotFrozen(ctx, tx, tenantID, id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM recipe WHERE id = ? AND tenant_id = ?", id, tenantID)
+		return err
+	})
+}
+
+// FindByExternalID ดึงข้อมูล Recipe จาก ID ของฐานข้อมูลสูตรอาหารภายนอก
+func (m *MySQLStore) FindByExternalID(ctx context.Context, tenantID, externalID, externalSource string) (_ Recipe, err error) {
+	defer wrapStoreErr("FindByExternalID", time.Now(), &err)
 
-	return nil
+	recipe, err := scanRecipe(m.db.QueryRowContext(ctx, "SELECT "+recipeColumns("description")+" FROM recipe WHERE external_id = ? AND external_source = ? AND tenant_id = ?", externalID, externalSource, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Recipe{}, &NotFoundError{Resource: "recipe", Key: externalSource + ":" + externalID}
+	}
+	if err != nil {
+		return Recipe{}, err
+	}
+	return recipe, nil
 }
 
 // นิยาม error ที่ custom ชื่อ NotFoundErr
 var ErrNotFound = errors.New("not found")
 
+// ErrDuplicate คือ error เมื่อข้อมูลที่เพิ่มซ้ำกับข้อมูลที่มีอยู่แล้ว
+var ErrDuplicate = errors.New("already exists")
+
+// isDuplicateKey ตรวจสอบว่า error มาจาก MySQL duplicate key (1062) หรือไม่
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
 // // RecipesHandler เป็น handler สำหรับตัวดำเนินการที่เกี่ยวกับ recipe
 type RecipesHandler struct {
-	store recipeStore
+	store    recipeStore
+	limits   RecipeLimits
+	metadata MetadataSchema
 }
 
 // // NewRecipesHandler สร้าง instance ใหม่ของ RecipesHandler
-func NewRecipesHandler(store recipeStore) *RecipesHandler {
-	return &RecipesHandler{store: store}
+func NewRecipesHandler(store recipeStore, limits RecipeLimits, metadata MetadataSchema) *RecipesHandler {
+	return &RecipesHandler{store: store, limits: limits, metadata: metadata}
 }
 
 // // main เป็นฟังก์ชันหลักที่ทำการสร้างเซิร์ฟเวอร์และกำหนด route
 func main() {
-	// สร้าง Gin router
-	router := gin.Default()
-
-	// สร้าง MySQL store และให้ implement ข้อมูลของ store
-	db, err := DBConnection()
+	// อ่านค่าตั้งจาก environment
+	cfg, err := LoadConfig()
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
-	defer db.Close()
 
-	store := NewMySQLStore(db)
-	recipesHandler := NewRecipesHandler(store)
-
-	// ลงทะเบียน Routes
-	router.GET("/", homePage)
-	router.GET("/recipes", recipesHandler.ListRecipes)
-	router.POST("/recipes", recipesHandler.CreateRecipe)
-	router.GET("/recipes/:id", recipesHandler.GetRecipe)
-	router.PUT("/recipes/:id", recipesHandler.UpdateRecipe)
-	router.DELETE("/recipes/:id", recipesHandler.DeleteRecipe)
+	// log ทั้งหมดรวมถึง log ของแพ็กเกจ log ออกผ่าน slog ตาม LOG_LEVEL และ LOG_FORMAT
+	logger := NewLogger(os.Stderr, cfg)
+	slog.SetDefault(logger)
+
+	// metric ของ runtime และของ connection pool ส่งออกที่ /metrics
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	// เลือก store ตาม STORE_BACKEND ถ้าเป็น memory จะทำงานได้โดยไม่ต้องมีฐานข้อมูล
+	var store recipeStore
+	var users userStore
+	var readOnlyReason string
+	lifecycle := NewLifecycle()
+	switch cfg.StoreBackend {
+	case "memory":
+		log.Println("STORE_BACKEND is memory, data will be lost on exit")
+		store = NewMemoryStore()
+		users = NewMemoryUserStore()
+	case "mysql":
+		// ฐานข้อมูล migration และการตรวจ schema เริ่มตามลำดับผ่าน lifecycle เพื่อให้รายงานได้ว่าส่วนใดล้มเหลว
+		var db *sql.DB
+		var schema int
+		lifecycle.Register(Component{
+			Name:         "database",
+			ConfigSource: "DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME",
+			Start: func(ctx context.Context) error {
+				var err error
+				db, err = DBConnection(ctx, cfg)
+				return err
+			},
+			Stop: func(ctx context.Context) error {
+				return db.Close()
+			},
+			Check: func(ctx context.Context) error {
+				return db.PingContext(ctx)
+			},
+		})
+
+		// รัน migration ตอนเริ่มเซิร์ฟเวอร์เฉพาะเมื่อเปิด MIGRATE_ON_START
+		lifecycle.Register(Component{
+			Name:         "migrations",
+			ConfigSource: "MIGRATE_ON_START, MIGRATION_LOCK_TIMEOUT",
+			DependsOn:    []string{"database"},
+			Timeout:      cfg.MigrationLockTimeout + defaultComponentTimeout,
+			Start: func(ctx context.Context) error {
+				if !cfg.MigrateOnStart {
+					return nil
+				}
+				return Migrate(ctx, db, cfg.MigrationLockTimeout)
+			},
+		})
+
+		// ถ้า schema ใหม่กว่าที่ binary นี้รองรับ จะยังให้บริการการอ่านแต่ปิดการเขียน
+		// ส่วน schema ที่เก่ากว่า minSchemaVersion ทำให้เริ่มเซิร์ฟเวอร์ไม่ได้ เพราะขาดคอลัมน์ที่ใช้อ่าน
+		lifecycle.Register(Component{
+			Name:         "schema",
+			ConfigSource: "MIGRATE_ON_START",
+			DependsOn:    []string{"migrations"},
+			Start: func(ctx context.Context) error {
+				var err error
+				schema, err = CheckSchemaCompatibility(ctx, db)
+				if errors.Is(err, ErrSchemaIncompatible) {
+					log.Printf("%v; serving reads only", err)
+					readOnlyReason = err.Error()
+					return nil
+				}
+				return err
+			},
+		})
+
+		if err := lifecycle.Start(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+		RegisterDBStats(registry, db, cfg.DBName)
+		store = NewMySQLStore(db, schema)
+		users = NewMySQLUserStore(db)
+	}
 
-	// เริ่มเซิร์ฟเวอร์
-	router.Run(":8081")
+	router, err := newRouter(routerDeps{
+		Config:         cfg,
+		Logger:         logger,
+		Registry:       registry,
+		Store:          store,
+		Users:          users,
+		Lifecycle:      lifecycle,
+		ReadOnlyReason: readOnlyReason,
+	})
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+
+	// เริ่มเซิร์ฟเวอร์ และหยุด component ทั้งหมดในลำดับย้อนกลับหลังจาก request ทั้งหมดเสร็จแล้ว
+	if err := serve(router, cfg); err != nil {
+		log.Printf("server: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := lifecycle.Stop(ctx); err != nil {
+		log.Printf("shutdown: %v", err)
 	}
 }
 
 // homePage คือ handler สำหรับ route หน้าแรก
 func homePage(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Welcome to the home page"})
+	RespondSuccess(c, http.StatusOK, gin.H{"message": "Welcome to the home page"})
 }
 
-// ListRecipes คือ handler สำหรับดึงรายการสูตรอาหารทั้งหมด
+// ListRecipes คือ handler สำหรับดึงรายการสูตรอาหารทีละหน้า
 func (h *RecipesHandler) ListRecipes(c *gin.Context) {
+	// อ่านเงื่อนไขการกรองจาก query string
+	filter := RecipeFilter{
+		Equipment: strings.TrimSpace(c.Query("equipment")),
+		Query:     strings.TrimSpace(c.Query("q")),
+	}
+	if v := c.Query("has_source"); v != "" {
+		hasSource, err := strconv.ParseBool(v)
+		if err != nil {
+			respondErr(c, http.StatusBadRequest, codeBadRequest, "has_source must be a boolean")
+			return
+		}
+		filter.HasSource = hasSource
+	}
+
+	metadata, err := h.metadata.metadataFilter(c)
+	if err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	filter.Metadata = metadata
+
+	// ตัดคำอธิบายให้สั้นลง เว้นแต่ client ขอคำอธิบายเต็มด้วย ?expand=description
+	expandDescription := c.Query("expand") == "description"
+	if !expandDescription {
+		filter.SummaryLength = h.limits.SummaryRunes
+	}
+
+	// อ่านการแบ่งหน้าและการเรียงลำดับ
+	opts, err := parseListOptions(c)
+	if err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	// ถ้าระบุ external_id และ external_source จะค้นหาจาก ID ภายนอกแทน
+	externalID, externalSource := c.Query("external_id"), c.Query("external_source")
+	if externalID != "" || externalSource != "" {
+		h.findByExternalID(c, externalID, externalSource, opts)
+		return
+	}
+
 	// เรียกใช้ store เพื่อดึงรายการสูตรอาหาร
-	recipes, err := h.store.List()
+	recipes, total, err := h.store.List(c.Request.Context(), tenantID(c), filter, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
 		return
 	}
 
+	if !expandDescription {
+		for i := range recipes {
+			recipes[i].Description, recipes[i].Truncated = truncateRunes(recipes[i].Description, h.limits.SummaryRunes)
+		}
+	}
+
 	// ส่งรายการสูตรอาหารกลับไป
-	c.JSON(http.StatusOK, recipes)
+	RespondSuccess(c, http.StatusOK, newRecipePage(c, recipes, total, opts))
+}
+
+// findByExternalID ส่งรายการสูตรอาหารที่ตรงกับ ID ภายนอก (มีได้มากที่สุดหนึ่งรายการ)
+func (h *RecipesHandler) findByExternalID(c *gin.Context, externalID, externalSource string, opts ListOptions) {
+	if externalID == "" || externalSource == "" {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "external_id and external_source must be given together")
+		return
+	}
+
+	recipes := []Recipe{}
+	recipe, err := h.store.FindByExternalID(c.Request.Context(), tenantID(c), externalID, externalSource)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+	if err == nil {
+		recipes = append(recipes, recipe)
+	}
+
+	// ผลลัพธ์มีได้ไม่เกินหนึ่งรายการ จึงมีเพียงหน้าเดียว
+	opts.Offset = 0
+	RespondSuccess(c, http.StatusOK, newRecipePage(c, recipes, len(recipes), opts))
+}
+
+// recipeID อ่าน id ของ Recipe จากพารามิเตอร์ URL และตอบ 400 ถ้าไม่ใช่ตัวเลข
+func recipeID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, "id must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// bindValidRecipe อ่านและตรวจสอบ Recipe จาก request body แล้วตอบ error เองถ้าไม่ผ่าน
+// JSON ที่ไม่ถูกต้องได้ 400 ส่วนฟิลด์ที่ไม่รู้จักหรือค่าที่ไม่ผ่านการตรวจสอบได้ 422 พร้อมรายละเอียดทุกฟิลด์
+func (h *RecipesHandler) bindValidRecipe(c *gin.Context, recipe *Recipe) bool {
+	err := bindRecipe(c, recipe)
+	var unknown *unknownFieldError
+	if errors.As(err, &unknown) {
+		RespondError(c, http.StatusUnprocessableEntity, []AppError{{Code: codeValidationFailed, Field: unknown.Field, Message: "is not a known field"}})
+		return false
+	}
+	if err != nil {
+		respondErr(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return false
+	}
+	if details := h.validateRecipe(recipe); len(details) > 0 {
+		RespondError(c, http.StatusUnprocessableEntity, details)
+		return false
+	}
+	return true
 }
 
 // CreateRecipe คือ handler สำหรับเพิ่มสูตรอาหารใหม่
 func (h *RecipesHandler) CreateRecipe(c *gin.Context) {
 	// ดึง request body และแปลงเป็นโครงสร้าง Recipe
 	var recipe Recipe
-	if err := c.ShouldBindJSON(&recipe); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !h.bindValidRecipe(c, &recipe) {
 		return
 	}
 
 	// เพิ่มสูตรอาหารใหม่
-	err := h.store.Add(recipe.Name, recipe)
+	id, err := h.store.Add(c.Request.Context(), tenantID(c), recipe)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondStoreErr(c, err)
 		return
 	}
 
-	// ส่งผลลัพธ์สำเร็จกลับ
-	c.JSON(http.StatusOK, gin.H{"status": "success"})
+	// ดึงข้อมูลที่บันทึกจริงกลับมา เพื่อให้ client ได้ค่าที่ฐานข้อมูลกำหนดด้วย
+	created, err := h.store.Get(c.Request.Context(), tenantID(c), id)
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	// ส่งข้อมูลสูตรอาหารที่บันทึกแล้วกลับไปพร้อม URL ของสูตรอาหาร
+	c.Header("Location", "/recipes/"+strconv.FormatInt(created.ID, 10))
+	RespondSuccess(c, http.StatusCreated, created)
 }
 
 // GetRecipe คือ handler สำหรับดึงข้อมูลสูตรอาหารจาก ID
 func (h *RecipesHandler) GetRecipe(c *gin.Context) {
 	// ดึงพารามิเตอร์ URL
-	id := c.Param("id")
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
 
 	// ดึงข้อมูลสูตรอาหารจาก store ด้วย ID
-	recipe, err := h.store.Get(id)
+	recipe, err := h.store.Get(c.Request.Context(), tenantID(c), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondStoreErr(c, err)
 		return
 	}
 
 	// ส่งข้อมูลสูตรอาหารกลับไป
-	c.JSON(http.StatusOK, recipe)
+	RespondSuccess(c, http.StatusOK, recipe)
 }
 
 // UpdateRecipe คือ handler สำหรับอัปเดตข้อมูลสูตรอาหาร
 func (h *RecipesHandler) UpdateRecipe(c *gin.Context) {
 	// ดึงพารามิเตอร์ URL
-	id := c.Param("id")
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
 
 	// ดึง request body และแปลงเป็นโครงสร้าง Recipe
 	var recipe Recipe
-	if err := c.ShouldBindJSON(&recipe); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !h.bindValidRecipe(c, &recipe) {
 		return
 	}
 
 	// เรียกใช้ store เพื่ออัปเดตสูตรอาหาร
-	err := h.store.Update(id, recipe)
+	err := h.store.Update(c.Request.Context(), tenantID(c), id, recipe)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondStoreErr(c, err)
 		return
 	}
 
-	// ส่งผลลัพธ์สำเร็จกลับ
-	c.JSON(http.StatusOK, gin.H{"status": "success"})
+	// ดึงข้อมูลที่บันทึกจริงกลับมา เพื่อให้ client ได้ updated_at ที่ฐานข้อมูลกำหนด
+	updated, err := h.store.Get(c.Request.Context(), tenantID(c), id)
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	// ส่งข้อมูลสูตรอาหารที่อัปเดตแล้วกลับไป
+	RespondSuccess(c, http.StatusOK, updated)
 }
 
 // DeleteRecipe คือ handler สำหรับลบสูตรอาหาร
 func (h *RecipesHandler) DeleteRecipe(c *gin.Context) {
 	// ดึงพารามิเตอร์ URL
-	id := c.Param("id")
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
 
 	// เรียกใช้ store เพื่อลบสูตรอาหาร
-	err := h.store.Remove(id)
+	err := h.store.Remove(c.Request.Context(), tenantID(c), id)
 	if err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondStoreErr(c, err)
 		return
 	}
 
 	// ส่งผลลัพธ์สำเร็จกลับ
-	c.JSON(http.StatusOK, gin.H{"status": "success"})
+	RespondSuccess(c, http.StatusOK, nil)
 }
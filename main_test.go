@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// mainProcessEnv บอก TestMainProcess ว่ากำลังรันเป็น process ลูกของ startMainProcess
+const mainProcessEnv = "GO_REST_DEMO_MAIN_PROCESS"
+
+// TestMainProcess ไม่ใช่ test จริง แต่เป็นจุดเข้าที่ startMainProcess ใช้รัน main ใน process ลูก
+func TestMainProcess(t *testing.T) {
+	if os.Getenv(mainProcessEnv) != "1" {
+		t.Skip("runs main only as a child of startMainProcess")
+	}
+	main()
+}
+
+// freeAddr คืน address บน loopback ที่ยังไม่มีใครใช้
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// startMainProcess รัน main ใน process ลูกด้วย environment ที่กำหนด และรอจน /healthz ตอบ
+// คืน base URL ของเซิร์ฟเวอร์ และ process ลูกที่ test ต้องหยุดเอง
+func startMainProcess(t *testing.T, env ...string) (string, *exec.Cmd) {
+	t.Helper()
+	addr := freeAddr(t)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), mainProcessEnv+"=1", "LISTEN_ADDR="+addr, "LOG_LEVEL=error")
+	cmd.Env = append(cmd.Env, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	baseURL := "http://" + addr
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(baseURL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			return baseURL, cmd
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v; stderr %s", err, stderr.String())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// runMainProcess รัน main ใน process ลูกที่คาดว่าจะจบเอง เช่นเมื่อค่าตั้งไม่ถูกต้อง
+// และคืน stderr กับ error ของการจบ process
+func runMainProcess(t *testing.T, env ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), mainProcessEnv+"=1", "LISTEN_ADDR="+freeAddr(t))
+	cmd.Env = append(cmd.Env, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return stderr.String(), err
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		<-done
+		t.Fatalf("server did not exit; stderr %s", stderr.String())
+		return "", nil
+	}
+}
+
+// expectFatalStartup ตรวจว่า main จบด้วย exit code ที่ไม่ใช่ 0 และ log ข้อความที่มี want แทนที่จะ panic
+func expectFatalStartup(t *testing.T, stderr string, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("server started despite the bad configuration; stderr %s", stderr)
+	}
+	if strings.Contains(stderr, "panic:") || strings.Contains(stderr, "goroutine ") {
+		t.Errorf("startup panicked instead of logging the error: %s", stderr)
+	}
+	if !strings.Contains(stderr, want) {
+		t.Errorf("stderr %q does not mention %q", stderr, want)
+	}
+}
+
+func TestBootsWithMemoryBackend(t *testing.T) {
+	baseURL, cmd := startMainProcess(t, "STORE_BACKEND=memory", "API_KEYS="+testAPIKey)
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/recipes", strings.NewReader(`{"name":"Pad Thai","description":"d","equipment":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiKeyHeader, testAPIKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /recipes status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	resp, err = http.Get(baseURL + resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET created recipe status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	// SIGTERM หยุดเซิร์ฟเวอร์ตามปกติ process จึงจบด้วย exit code 0
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("server exited with %v", err)
+	}
+}
+
+func TestBadUnitsFileStopsStartup(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "units.json")
+	stderr, err := runMainProcess(t, "STORE_BACKEND=memory", "UNITS_FILE="+missing)
+	expectFatalStartup(t, stderr, err, "UNITS_FILE")
+	if !strings.Contains(stderr, missing) {
+		t.Errorf("stderr %q does not name the file", stderr)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "units.json")
+	if err := os.WriteFile(invalid, []byte(`{"units":[{"symbol":"pinch","factor":0}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stderr, err = runMainProcess(t, "STORE_BACKEND=memory", "UNITS_FILE="+invalid)
+	expectFatalStartup(t, stderr, err, "positive factor")
+}
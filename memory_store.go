@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryRecipe คือ Recipe หนึ่งรายการใน MemoryStore พร้อมข้อมูลที่ไม่ได้อยู่ใน Recipe
+type memoryRecipe struct {
+	recipe       Recipe
+	frozen       bool
+	frozenReason string
+	ingredients  []Ingredient
+}
+
+// snapshot คืนสำเนาของ Recipe พร้อมวัตถุดิบ เหมือนที่ MySQLStore.Get คืนค่า
+func (e *memoryRecipe) snapshot() Recipe {
+	recipe := cloneRecipe(e.recipe)
+	recipe.Ingredients = append([]Ingredient{}, e.ingredients...)
+	return recipe
+}
+
+// MemoryStore เป็น implement ของ recipeStore ที่เก็บข้อมูลไว้ในหน่วยความจำ
+// ใช้สำหรับการพัฒนาบนเครื่องโดยไม่ต้องมีฐานข้อมูล ข้อมูลจะหายเมื่อปิดโปรแกรม
+type MemoryStore struct {
+	mu      sync.RWMutex
+	tenants map[string]map[int64]*memoryRecipe
+	nextID  int64
+
+	nextIngredientID int64
+}
+
+// NewMemoryStore สร้าง instance ใหม่ของ memory store
+func NewMemoryStore() recipeStore {
+	return &MemoryStore{tenants: make(map[string]map[int64]*memoryRecipe)}
+}
+
+// cloneRecipe คืนสำเนาของ recipe ที่ไม่ใช้ slice ร่วมกับต้นฉบับ
+func cloneRecipe(recipe Recipe) Recipe {
+	recipe.Equipment = append([]string{}, recipe.Equipment...)
+	if recipe.Metadata != nil {
+		metadata := make(map[string]interface{}, len(recipe.Metadata))
+		for key, value := range recipe.Metadata {
+			metadata[key] = value
+		}
+		recipe.Metadata = metadata
+	}
+	return recipe
+}
+
+// recipes คืน Recipe ทั้งหมดของ tenant โดยสร้าง map ใหม่ถ้ายังไม่มี
+// ผู้เรียกต้องถือ write lock
+func (m *MemoryStore) recipes(tenantID string) map[int64]*memoryRecipe {
+	recipes, ok := m.tenants[tenantID]
+	if !ok {
+		recipes = make(map[int64]*memoryRecipe)
+		m.tenants[tenantID] = recipes
+	}
+	return recipes
+}
+
+// conflicts ตรวจสอบว่ามี Recipe อื่นที่ไม่ใช่ id ใช้ชื่อหรือ ID ภายนอกเดียวกันอยู่แล้วหรือไม่
+// เหมือน unique key ของตาราง recipe ใน MySQL ซึ่งเปรียบเทียบชื่อโดยไม่สนใจตัวพิมพ์ และคืน ConflictError ของฟิลด์ที่ซ้ำ
+func conflicts(recipes map[int64]*memoryRecipe, id int64, recipe Recipe) error {
+	for other, entry := range recipes {
+		if other == id {
+			continue
+		}
+		if strings.EqualFold(entry.recipe.Name, recipe.Name) {
+			return recipeConflict(recipe, "name")
+		}
+		if recipe.ExternalID != "" && recipe.ExternalSource != "" &&
+			entry.recipe.ExternalID == recipe.ExternalID && entry.recipe.ExternalSource == recipe.ExternalSource {
+			return recipeConflict(recipe, "external_id")
+		}
+	}
+	return nil
+}
+
+// containsFold ตรวจสอบว่า values มี s อยู่หรือไม่โดยไม่สนใจตัวพิมพ์เหมือน collation ของ MySQL
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// findByName ค้นหา Recipe ตามชื่อโดยไม่สนใจตัวพิมพ์เหมือน collation ของ MySQL ผู้เรียกต้องถือ lock
+func findByName(recipes map[int64]*memoryRecipe, name string) (*memoryRecipe, bool) {
+	for _, entry := range recipes {
+		if strings.EqualFold(entry.recipe.Name, name) {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Add เพิ่ม Recipe ใหม่และคืน id ที่สร้างให้
+func (m *MemoryStore) Add(_ context.Context, tenantID string, recipe Recipe) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := add(m.recipes(tenantID), m.nextID+1, recipe); err != nil {
+		return 0, err
+	}
+	m.nextID++
+	return m.nextID, nil
+}
+
+// add เพิ่ม recipe ลงใน recipes ด้วย id ที่กำหนด ผู้เรียกต้องถือ write lock
+func add(recipes map[int64]*memoryRecipe, id int64, recipe Recipe) error {
+	if err := conflicts(recipes, 0, recipe); err != nil {
+		return err
+	}
+
+	recipe = cloneRecipe(recipe)
+	recipe.ID = id
+	recipe.Truncated = false
+	recipe.Ingredients = nil
+	recipe.CreatedAt = time.Now().UTC()
+	recipe.UpdatedAt = recipe.CreatedAt
+	recipes[id] = &memoryRecipe{recipe: recipe}
+	return nil
+}
+
+// AddBatch เพิ่ม Recipe หลายรายการด้วยความหมายเดียวกับ MySQLStore.AddBatch
+// รายการจะถูกเพิ่มลงในสำเนาของข้อมูลก่อน และแทนที่ข้อมูลจริงเมื่อทุกรายการสำเร็จเท่านั้น
+func (m *MemoryStore) AddBatch(_ context.Context, tenantID string, recipes []Recipe) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[int64]*memoryRecipe)
+	for id, entry := range m.recipes(tenantID) {
+		snapshot[id] = entry
+	}
+
+	nextID := m.nextID
+	ids := make([]int64, len(recipes))
+	for i, recipe := range recipes {
+		if err := add(snapshot, nextID+1, recipe); err != nil {
+			return nil, &BatchAddError{Index: i, Name: recipe.Name, Err: err}
+		}
+		nextID++
+		ids[i] = nextID
+	}
+
+	m.tenants[tenantID] = snapshot
+	m.nextID = nextID
+	return ids, nil
+}
+
+// Get ดึงข้อมูล Recipe
+func (m *MemoryStore) Get(_ context.Context, tenantID string, id int64) (Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.tenants[tenantID][id]
+	if !ok {
+		return Recipe{}, recipeNotFound(id)
+	}
+	return entry.snapshot(), nil
+}
+
+// GetMany ดึงข้อมูล Recipe หลายรายการตามชื่อด้วยความหมายเดียวกับ MySQLStore.GetMany
+func (m *MemoryStore) GetMany(_ context.Context, tenantID string, names []string) (map[string]Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	recipes := make(map[string]Recipe)
+	for _, name := range names {
+		if entry, ok := findByName(m.tenants[tenantID], name); ok {
+			recipes[name] = cloneRecipe(entry.recipe)
+		}
+	}
+	return recipes, nil
+}
+
+// List ดึงรายการ Recipe หนึ่งหน้าตามเงื่อนไขและลำดับที่กำหนด พร้อมจำนวนทั้งหมดที่ตรงกับเงื่อนไข
+// SummaryLength ไม่มีผลเพราะข้อมูลอยู่ในหน่วยความจำแล้ว handler จะตัดคำอธิบายเอง
+func (m *MemoryStore) List(_ context.Context, tenantID string, filter RecipeFilter, opts ListOptions) ([]Recipe, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []*memoryRecipe
+	for _, entry := range m.tenants[tenantID] {
+		if filter.HasSource && entry.recipe.SourceURL == "" {
+			continue
+		}
+		if filter.Equipment != "" && !containsFold(entry.recipe.Equipment, filter.Equipment) {
+			continue
+		}
+		if filter.Query != "" && !matchesQuery(entry.recipe, filter.Query) {
+			continue
+		}
+		if !metadataMatches(entry.recipe.Metadata, filter.Metadata) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	// เรียงแบบเดียวกับ ListOptions.orderBy โดยใช้ id เป็นตัวตัดสินเมื่อค่าเท่ากัน
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if opts.Order == "desc" {
+			a, b = b, a
+		}
+		switch {
+		case opts.SortBy == "name" && !strings.EqualFold(a.recipe.Name, b.recipe.Name):
+			return strings.ToLower(a.recipe.Name) < strings.ToLower(b.recipe.Name)
+		case opts.SortBy == "created_at" && !a.recipe.CreatedAt.Equal(b.recipe.CreatedAt):
+			return a.recipe.CreatedAt.Before(b.recipe.CreatedAt)
+		case opts.SortBy == "updated_at" && !a.recipe.UpdatedAt.Equal(b.recipe.UpdatedAt):
+			return a.recipe.UpdatedAt.Before(b.recipe.UpdatedAt)
+		}
+		return a.recipe.ID < b.recipe.ID
+	})
+
+	recipes := []Recipe{}
+	for i := opts.Offset; i < len(matched) && i < opts.Offset+opts.Limit; i++ {
+		recipes = append(recipes, cloneRecipe(matched[i].recipe))
+	}
+	return recipes, len(matched), nil
+}
+
+// Update อัพเดตข้อมูล Recipe รวมถึงชื่อ Recipe ที่ถูก freeze จะแก้ไขไม่ได้
+func (m *MemoryStore) Update(_ context.Context, tenantID string, id int64, recipe Recipe) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recipes := m.recipes(tenantID)
+	entry, ok := recipes[id]
+	if !ok {
+		return recipeNotFound(id)
+	}
+	return update(recipes, entry, func(Recipe) Recipe { return recipe })
+}
+
+// update นำ change ไปใช้กับ entry ใน recipes ผู้เรียกต้องถือ write lock
+func update(recipes map[int64]*memoryRecipe, entry *memoryRecipe, change func(Recipe) Recipe) error {
+	if entry.frozen {
+		return fmt.Errorf("%w: %s", ErrFrozen, entry.frozenReason)
+	}
+
+	recipe := cloneRecipe(change(cloneRecipe(entry.recipe)))
+	recipe.ID = entry.recipe.ID
+	recipe.CreatedAt = entry.recipe.CreatedAt
+	recipe.UpdatedAt = time.Now().UTC()
+	recipe.Truncated = false
+	recipe.Ingredients = nil
+	if err := conflicts(recipes, recipe.ID, recipe); err != nil {
+		return err
+	}
+	entry.recipe = recipe
+	return nil
+}
+
+// Patch แก้ไขเฉพาะฟิลด์ที่มีใน patch และคืนค่า Recipe หลังแก้ไข
+func (m *MemoryStore) Patch(_ context.Context, tenantID string, id int64, patch RecipePatch) (Recipe, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recipes := m.recipes(tenantID)
+	entry, ok := recipes[id]
+	if !ok {
+		return Recipe{}, recipeNotFound(id)
+	}
+	if err := update(recipes, entry, patch.apply); err != nil {
+		return Recipe{}, err
+	}
+	return entry.snapshot(), nil
+}
+
+// Remove ลบ Recipe Recipe ที่ถูก freeze จะลบไม่ได้
+func (m *MemoryStore) Remove(_ context.Context, tenantID string, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recipes := m.recipes(tenantID)
+	entry, ok := recipes[id]
+	if !ok {
+		return recipeNotFound(id)
+	}
+	if entry.frozen {
+		return fmt.Errorf("%w: %s", ErrFrozen, entry.frozenReason)
+	}
+	delete(recipes, id)
+	return nil
+}
+
+// FindByExternalID ดึงข้อมูล Recipe จาก ID ของฐานข้อมูลสูตรอาหารภายนอก
+func (m *MemoryStore) FindByExternalID(_ context.Context, tenantID, externalID, externalSource string) (Recipe, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entry := range m.tenants[tenantID] {
+		if entry.recipe.ExternalID == externalID && entry.recipe.ExternalSource == externalSource {
+			return cloneRecipe(entry.recipe), nil
+		}
+	}
+	return Recipe{}, &NotFoundError{Resource: "recipe", Key: externalSource + ":" + externalID}
+}
+
+// ListEquipment ดึงรายชื่ออุปกรณ์ที่ไม่ซ้ำกันพร้อมจำนวนสูตรอาหารที่ใช้
+func (m *MemoryStore) ListEquipment(_ context.Context, tenantID string) ([]EquipmentCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, entry := range m.tenants[tenantID] {
+		for _, name := range entry.recipe.Equipment {
+			counts[name]++
+		}
+	}
+
+	equipment := []EquipmentCount{}
+	for name, count := range counts {
+		equipment = append(equipment, EquipmentCount{Name: name, Count: count})
+	}
+	sort.Slice(equipment, func(i, j int) bool {
+		return equipment[i].Name < equipment[j].Name
+	})
+	return equipment, nil
+}
+
+// PatchBatch แก้ไข Recipe หลายรายการด้วยความหมายเดียวกับ MySQLStore.PatchBatch
+// ในโหมด transaction เดียวจะแก้ไขสำเนาของข้อมูลก่อน และแทนที่ข้อมูลจริงเมื่อทุกรายการสำเร็จเท่านั้น
+func (m *MemoryStore) PatchBatch(_ context.Context, tenantID string, items []RecipePatchItem, continueOnError bool) ([]BatchItemResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recipes := m.recipes(tenantID)
+	if !continueOnError {
+		snapshot := make(map[int64]*memoryRecipe, len(recipes))
+		for id, entry := range recipes {
+			copied := *entry
+			snapshot[id] = &copied
+		}
+		recipes = snapshot
+	}
+
+	results := make([]BatchItemResult, len(items))
+	failed := false
+	for i, item := range items {
+		var err error = &NotFoundError{Resource: "recipe", Key: item.Name}
+		if entry, ok := findByName(recipes, item.Name); ok {
+			err = update(recipes, entry, item.Patch.apply)
+		}
+		result, ok := batchItemResult(item.Name, err)
+		if !ok {
+			return nil, err
+		}
+		results[i] = result
+		failed = failed || err != nil
+	}
+
+	if continueOnError {
+		return results, nil
+	}
+	if failed {
+		markRolledBack(results)
+		return results, nil
+	}
+	m.tenants[tenantID] = recipes
+	return results, nil
+}
+
+// SetFrozen ล็อกหรือปลดล็อก Recipe โดย reason จะถูกเก็บไว้เฉพาะตอนล็อก
+func (m *MemoryStore) SetFrozen(_ context.Context, tenantID string, id int64, frozen bool, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.tenants[tenantID][id]
+	if !ok {
+		return recipeNotFound(id)
+	}
+	if !frozen {
+		reason = ""
+	}
+	entry.frozen = frozen
+	entry.frozenReason = reason
+	return nil
+}
+
+// MemoryUserStore เป็น implement ของ userStore ที่เก็บข้อมูลไว้ในหน่วยความจำ
+type MemoryUserStore struct {
+	mu sync.RWMutex
+	// users ใช้ชื่อผู้ใช้ตัวพิมพ์เล็กเป็น key เพราะ collation ของตาราง users ไม่สนตัวพิมพ์
+	users  map[string]User
+	nextID int64
+}
+
+// NewMemoryUserStore สร้าง instance ใหม่ของ memory user store
+func NewMemoryUserStore() userStore {
+	return &MemoryUserStore{users: make(map[string]User)}
+}
+
+// AddUser เพิ่ม User ใหม่ โดยใช้ tenant และ role เริ่มต้นเหมือนค่า default ของตาราง users
+func (m *MemoryUserStore) AddUser(_ context.Context, user User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.ToLower(user.Username)
+	if _, ok := m.users[key]; ok {
+		return &ConflictError{Field: "username", Value: user.Username}
+	}
+	for _, other := range m.users {
+		if strings.EqualFold(other.Email, user.Email) {
+			return &ConflictError{Field: "email", Value: user.Email}
+		}
+	}
+
+	m.nextID++
+	user.ID = m.nextID
+	user.TenantID = defaultTenantID
+	user.Role = "user"
+	m.users[key] = user
+	return nil
+}
+
+// GetUserByUsername ดึงข้อมูล User ด้วยชื่อผู้ใช้โดยไม่สนตัวพิมพ์
+func (m *MemoryUserStore) GetUserByUsername(_ context.Context, username string) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[strings.ToLower(username)]
+	if !ok {
+		return User{}, &NotFoundError{Resource: "user", Key: username}
+	}
+	return user, nil
+}
+
+// AddIngredient เพิ่มวัตถุดิบให้ Recipe ด้วยความหมายเดียวกับ MySQLStore.AddIngredient
+func (m *MemoryStore) AddIngredient(_ context.Context, tenantID string, recipeID int64, ingredient Ingredient) (Ingredient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.tenants[tenantID][recipeID]
+	if !ok {
+		return Ingredient{}, recipeNotFound(recipeID)
+	}
+	if entry.frozen {
+		return Ingredient{}, fmt.Errorf("%w: %s", ErrFrozen, entry.frozenReason)
+	}
+
+	m.nextIngredientID++
+	ingredient.ID = m.nextIngredientID
+	entry.ingredients = append(entry.ingredients, ingredient)
+	return ingredient, nil
+}
+
+// ListIngredients ดึงวัตถุดิบของ Recipe เรียงตามลำดับที่เพิ่ม
+func (m *MemoryStore) ListIngredients(_ context.Context, tenantID string, recipeID int64) ([]Ingredient, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.tenants[tenantID][recipeID]
+	if !ok {
+		return nil, recipeNotFound(recipeID)
+	}
+	return append([]Ingredient{}, entry.ingredients...), nil
+}
+
+// RemoveIngredient ลบวัตถุดิบออกจาก Recipe
+func (m *MemoryStore) RemoveIngredient(_ context.Context, tenantID string, recipeID, ingredientID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.tenants[tenantID][recipeID]
+	if !ok {
+		return recipeNotFound(recipeID)
+	}
+	if entry.frozen {
+		return fmt.Errorf("%w: %s", ErrFrozen, entry.frozenReason)
+	}
+	for i, ingredient := range entry.ingredients {
+		if ingredient.ID == ingredientID {
+			entry.ingredients = append(entry.ingredients[:i:i], entry.ingredients[i+1:]...)
+			return nil
+		}
+	}
+	return ingredientNotFound(ingredientID)
+}
@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+func TestMemoryStoreConformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T) recipeStore {
+		return NewMemoryStore()
+	})
+}
+
+func TestMemoryUserStoreConformance(t *testing.T) {
+	testUserStoreConformance(t, NewMemoryUserStore())
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ชนิดของค่าที่ฟิลด์ metadata รองรับ
+const (
+	metadataString  = "string"
+	metadataNumber  = "number"
+	metadataBoolean = "boolean"
+)
+
+// metadataQueryPrefix คือ prefix ของ query string ที่ใช้กรองด้วย metadata เช่น ?metadata.spice_level=hot
+const metadataQueryPrefix = "metadata."
+
+// MetadataField คือนิยามของฟิลด์ metadata หนึ่งฟิลด์
+type MetadataField struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Allowed  []string `json:"allowed,omitempty"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// MetadataSchema คือ schema ของ metadata ที่แต่ละ deployment กำหนดเอง
+// ถ้า AllowUnknown เป็น false ฟิลด์ที่ไม่อยู่ใน Fields จะไม่ถูกรับ
+type MetadataSchema struct {
+	Fields       []MetadataField `json:"fields"`
+	AllowUnknown bool            `json:"allow_unknown"`
+}
+
+// LoadMetadataSchema อ่าน MetadataSchema จากไฟล์ JSON ที่ path
+// ถ้า path ว่างจะคืน schema ที่ไม่มีฟิลด์ใดเลย ซึ่งทำให้ไม่รับ metadata
+func LoadMetadataSchema(path string) (MetadataSchema, error) {
+	var schema MetadataSchema
+	if path == "" {
+		return schema, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return schema, err
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return schema, err
+	}
+	for _, field := range schema.Fields {
+		switch {
+		case field.Name == "":
+			return schema, errors.New("metadata schema: every field needs a name")
+		case field.Type != metadataString && field.Type != metadataNumber && field.Type != metadataBoolean:
+			return schema, fmt.Errorf("metadata schema: field %q has unknown type %q", field.Name, field.Type)
+		case len(field.Allowed) > 0 && field.Type != metadataString:
+			return schema, fmt.Errorf("metadata schema: allowed values are only supported for string field %q", field.Name)
+		}
+	}
+	return schema, nil
+}
+
+// field ค้นหานิยามของฟิลด์ตามชื่อ
+func (s MetadataSchema) field(name string) (MetadataField, bool) {
+	for _, field := range s.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return MetadataField{}, false
+}
+
+// validate ตรวจสอบ metadata ตอนเขียนข้อมูล และคืนรายละเอียดของทุกฟิลด์ที่ไม่ผ่าน
+// การอ่านข้อมูลจะไม่ตรวจสอบ เพื่อให้ schema ที่เปลี่ยนไม่ทำให้ข้อมูลเดิมอ่านไม่ได้
+func (s MetadataSchema) validate(metadata map[string]interface{}) []AppError {
+	var details []AppError
+	for _, field := range s.Fields {
+		if _, ok := metadata[field.Name]; field.Required && !ok {
+			details = append(details, AppError{Code: codeValidationFailed, Field: metadataQueryPrefix + field.Name, Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, ok := s.field(key)
+		if !ok {
+			if !s.AllowUnknown {
+				details = append(details, AppError{Code: codeValidationFailed, Field: metadataQueryPrefix + key, Message: "is not a known metadata field"})
+			}
+			continue
+		}
+		if message := field.check(metadata[key]); message != "" {
+			details = append(details, AppError{Code: codeValidationFailed, Field: metadataQueryPrefix + key, Message: message})
+		}
+	}
+	return details
+}
+
+// check ตรวจสอบชนิดและค่าที่อนุญาตของ value และคืนข้อความ error ถ้าไม่ผ่าน
+func (f MetadataField) check(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if f.Type != metadataString {
+			return "must be a " + f.Type
+		}
+		if len(f.Allowed) > 0 && !containsString(f.Allowed, v) {
+			return "must be one of " + strings.Join(f.Allowed, ", ")
+		}
+	case float64:
+		if f.Type != metadataNumber {
+			return "must be a " + f.Type
+		}
+	case bool:
+		if f.Type != metadataBoolean {
+			return "must be a " + f.Type
+		}
+	default:
+		return "must be a " + f.Type
+	}
+	return ""
+}
+
+// metadataFilter อ่านเงื่อนไข ?metadata.<name>=<value> จาก query string
+// กรองได้เฉพาะฟิลด์ที่อยู่ใน schema เท่านั้น
+func (s MetadataSchema) metadataFilter(c *gin.Context) (map[string]string, error) {
+	filter := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, metadataQueryPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, metadataQueryPrefix)
+		if _, ok := s.field(name); !ok {
+			return nil, fmt.Errorf("%s is not a filterable metadata field", key)
+		}
+		filter[name] = values[0]
+	}
+	return filter, nil
+}
+
+// metadataPath คืน JSON path ของฟิลด์ metadata สำหรับ JSON_EXTRACT
+func metadataPath(name string) string {
+	quoted, _ := json.Marshal(name)
+	return "$." + string(quoted)
+}
+
+// metadataValue แปลงค่าของ metadata เป็นสตริงแบบเดียวกับที่ JSON_UNQUOTE คืนค่า เพื่อใช้เปรียบเทียบกับตัวกรอง
+func metadataValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// metadataMatches ตรวจสอบว่า metadata มีค่าตรงกับตัวกรองทุกฟิลด์
+func metadataMatches(metadata map[string]interface{}, filter map[string]string) bool {
+	for name, want := range filter {
+		value, ok := metadata[name]
+		if !ok || metadataValue(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// marshalMetadata แปลง metadata เป็น JSON สำหรับเก็บในฐานข้อมูล metadata ว่างจะเก็บเป็น NULL
+func marshalMetadata(metadata map[string]interface{}) (sql.NullString, error) {
+	if len(metadata) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unmarshalMetadata แปลง JSON จากฐานข้อมูลกลับเป็น metadata
+func unmarshalMetadata(data sql.NullString) (map[string]interface{}, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(data.String), &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testMetadataSchema คือ schema ของ metadata ที่ test ส่วนใหญ่ใช้
+const testMetadataSchema = `{
+	"fields": [
+		{"name": "spice_level", "type": "string", "allowed": ["mild", "hot"], "required": true},
+		{"name": "servings", "type": "number"},
+		{"name": "halal", "type": "boolean"}
+	]
+}`
+
+// writeMetadataSchema เขียน schema ลงในไฟล์ชั่วคราวและคืน path
+func writeMetadataSchema(t *testing.T, schema string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(path, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newMetadataTestServer สร้างเซิร์ฟเวอร์บน store ที่กำหนดโดยใช้ schema ของ metadata ที่ระบุ
+func newMetadataTestServer(t *testing.T, store recipeStore, schema string) *testServer {
+	t.Helper()
+	path := writeMetadataSchema(t, schema)
+	return newTestServerWithStore(t, store, func(cfg *Config) {
+		cfg.MetadataSchemaFile = path
+	})
+}
+
+func TestLoadMetadataSchemaErrors(t *testing.T) {
+	cases := map[string]string{
+		"not json":            `{"fields":`,
+		"missing name":        `{"fields":[{"type":"string"}]}`,
+		"unknown type":        `{"fields":[{"name":"sku","type":"date"}]}`,
+		"allowed on a number": `{"fields":[{"name":"servings","type":"number","allowed":["1"]}]}`,
+	}
+	for name, schema := range cases {
+		if _, err := LoadMetadataSchema(writeMetadataSchema(t, schema)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := LoadMetadataSchema(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file: expected an error")
+	}
+}
+
+func TestMetadataValidation(t *testing.T) {
+	server := newMetadataTestServer(t, NewMemoryStore(), testMetadataSchema)
+
+	cases := []struct {
+		name, metadata string
+		fields         []string
+	}{
+		{"missing required", `{"servings":2}`, []string{"metadata.spice_level"}},
+		{"value not allowed", `{"spice_level":"extreme"}`, []string{"metadata.spice_level"}},
+		{"wrong types", `{"spice_level":"hot","servings":"two","halal":"yes"}`, []string{"metadata.halal", "metadata.servings"}},
+		{"unknown key", `{"spice_level":"hot","sku":"A-1"}`, []string{"metadata.sku"}},
+		{"nested value", `{"spice_level":"hot","servings":{"min":1}}`, []string{"metadata.servings"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"name":"Pad Thai","description":"d","equipment":[],"metadata":` + tc.metadata + `}`
+			w := server.do(http.MethodPost, "/recipes", body, writeHeaders...)
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+			var fields []string
+			for _, e := range decodeEnvelope(t, w, nil).Errors {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tc.fields)
+			}
+		})
+	}
+
+	created := server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[],"metadata":{"spice_level":"hot","servings":2,"halal":true}}`)
+	if created.Metadata["spice_level"] != "hot" || created.Metadata["servings"] != float64(2) || created.Metadata["halal"] != true {
+		t.Errorf("metadata = %v", created.Metadata)
+	}
+}
+
+func TestMetadataAllowUnknown(t *testing.T) {
+	server := newMetadataTestServer(t, NewMemoryStore(), `{"fields":[{"name":"spice_level","type":"string"}],"allow_unknown":true}`)
+	created := server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[],"metadata":{"spice_level":"hot","sku":"A-1"}}`)
+	if created.Metadata["sku"] != "A-1" {
+		t.Errorf("metadata = %v, want the open extension key kept", created.Metadata)
+	}
+
+	// ฟิลด์ที่รู้จักยังถูกตรวจชนิดอยู่
+	w := server.do(http.MethodPost, "/recipes", `{"name":"Tom Yum","description":"d","equipment":[],"metadata":{"spice_level":3}}`, writeHeaders...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+}
+
+func TestMetadataFilter(t *testing.T) {
+	server := newMetadataTestServer(t, NewMemoryStore(), testMetadataSchema)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[],"metadata":{"spice_level":"mild","servings":2,"halal":true}}`)
+	server.createRecipe(t, `{"name":"Tom Yum","description":"d","equipment":[],"metadata":{"spice_level":"hot","servings":4,"halal":false}}`)
+	server.createRecipe(t, `{"name":"Larb","description":"d","equipment":[],"metadata":{"spice_level":"hot","servings":2}}`)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"metadata.spice_level=hot", []string{"Tom Yum", "Larb"}},
+		{"metadata.servings=2", []string{"Pad Thai", "Larb"}},
+		{"metadata.halal=true", []string{"Pad Thai"}},
+		{"metadata.spice_level=hot&metadata.servings=2", []string{"Larb"}},
+		{"metadata.spice_level=medium", []string{}},
+	}
+	for _, tc := range cases {
+		page := server.listRecipes(t, "/recipes?"+tc.query)
+		if got := recipeNames(page.Recipes); strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("?%s = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	// กรองได้เฉพาะฟิลด์ที่อยู่ใน schema
+	w := server.do(http.MethodGet, "/recipes?metadata.sku=A-1", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+func TestMetadataSchemaChangeKeepsReadsWorking(t *testing.T) {
+	store := NewMemoryStore()
+	before := newMetadataTestServer(t, store, `{"fields":[{"name":"sku","type":"string"}]}`)
+	before.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[],"metadata":{"sku":"A-1"}}`)
+
+	// schema ใหม่ไม่มี sku และบังคับ spice_level แต่ข้อมูลเดิมยังอ่านได้
+	after := newMetadataTestServer(t, store, testMetadataSchema)
+	w := after.do(http.MethodGet, "/recipes/1", "")
+	expectStatus(t, w, http.StatusOK)
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if recipe.Metadata["sku"] != "A-1" {
+		t.Errorf("metadata = %v after a schema change", recipe.Metadata)
+	}
+	if page := after.listRecipes(t, "/recipes"); page.Total != 1 {
+		t.Errorf("list total = %d after a schema change", page.Total)
+	}
+
+	// การเขียนครั้งถัดไปต้องเป็นไปตาม schema ใหม่
+	w = after.do(http.MethodPut, "/recipes/1", `{"name":"Pad Thai","description":"d","equipment":[],"metadata":{"sku":"A-1"}}`, writeHeaders...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+}
+
+func TestBadMetadataSchemaFileStopsStartup(t *testing.T) {
+	path := writeMetadataSchema(t, `{"fields":[{"name":"sku","type":"date"}]}`)
+	stderr, err := runMainProcess(t, "STORE_BACKEND=memory", "METADATA_SCHEMA_FILE="+path)
+	expectFatalStartup(t, stderr, err, "METADATA_SCHEMA_FILE")
+	if !strings.Contains(stderr, "unknown type") {
+		t.Errorf("stderr %q does not explain the schema error", stderr)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute คือค่า label route ของ request ที่ไม่ตรงกับ route ใด เพื่อไม่ให้ path ที่สุ่มมาเพิ่มจำนวน label
+const unmatchedRoute = "unmatched"
+
+// Metrics คือ metric ของ HTTP request ที่ส่งออกผ่าน /metrics
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics สร้าง Metrics และลงทะเบียนกับ reg
+// ใช้ registry ที่ส่งเข้ามาแทน registry กลาง เพื่อให้แต่ละ instance มี metric แยกกัน
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// Middleware บันทึกจำนวนและเวลาของแต่ละ request โดยใช้ route template เช่น /recipes/:id เป็น label
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterDBStats ลงทะเบียน collector ที่อ่าน db.Stats() เช่นจำนวน connection ที่ใช้อยู่และจำนวนครั้งที่ต้องรอ
+func RegisterDBStats(reg prometheus.Registerer, db *sql.DB, dbName string) {
+	reg.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// MetricsHandler คือ handler ของ /metrics สำหรับ metric ใน reg
+func MetricsHandler(reg *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
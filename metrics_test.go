@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareCountsByRouteTemplate(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/recipes/:id", func(c *gin.Context) {
+		if c.Param("id") == "404" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/recipes/1", "/recipes/2", "/recipes/404", "/nope/123", "/nope/456"} {
+		performRequest(router, http.MethodGet, path, "")
+	}
+
+	// path ที่ต่างกันใช้ label เดียวกันตาม route template และ path ที่ไม่ตรง route ใดรวมเป็น unmatched
+	expected := `
+# HELP http_requests_total Number of HTTP requests by method, route template and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/recipes/:id",status="200"} 2
+http_requests_total{method="GET",route="/recipes/:id",status="404"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 2
+`
+	if err := testutil.CollectAndCompare(metrics.requests, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(metrics.duration); got != 2 {
+		t.Errorf("duration has %d series, want 2 (one per route)", got)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	server.do(http.MethodGet, "/recipes/1", "")
+	server.do(http.MethodGet, "/recipes/99", "")
+
+	expected := `
+# HELP http_requests_total Number of HTTP requests by method, route template and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/recipes/:id",status="200"} 1
+http_requests_total{method="GET",route="/recipes/:id",status="404"} 1
+http_requests_total{method="POST",route="/recipes",status="201"} 1
+`
+	if err := testutil.GatherAndCompare(server.registry, strings.NewReader(expected), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	w := server.do(http.MethodGet, "/metrics", "")
+	expectStatus(t, w, http.StatusOK)
+	for _, name := range []string{"http_requests_total", "http_request_duration_seconds_bucket"} {
+		if !strings.Contains(w.Body.String(), name) {
+			t.Errorf("/metrics has no %s", name)
+		}
+	}
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	// sql.Open ไม่เชื่อมต่อจนกว่าจะใช้งาน จึงอ่าน Stats ได้โดยไม่ต้องมีฐานข้อมูล
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:1)/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	registry := prometheus.NewRegistry()
+	RegisterDBStats(registry, db, "web_lek")
+	expected := `
+# HELP go_sql_in_use_connections The number of connections currently in use.
+# TYPE go_sql_in_use_connections gauge
+go_sql_in_use_connections{db_name="web_lek"} 0
+# HELP go_sql_open_connections The number of established connections both in use and idle.
+# TYPE go_sql_open_connections gauge
+go_sql_open_connections{db_name="web_lek"} 0
+# HELP go_sql_wait_count_total The total number of connections waited for.
+# TYPE go_sql_wait_count_total counter
+go_sql_wait_count_total{db_name="web_lek"} 0
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"go_sql_open_connections", "go_sql_in_use_connections", "go_sql_wait_count_total"); err != nil {
+		t.Error(err)
+	}
+}
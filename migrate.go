@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationLockName คือชื่อ advisory lock ที่ใช้ป้องกันไม่ให้หลาย instance รัน migration พร้อมกัน
+const migrationLockName = "go_rest_demo_migrations"
+
+// defaultMigrationLockTimeout คือเวลารอ lock เริ่มต้น
+const defaultMigrationLockTimeout = 60 * time.Second
+
+// migration คือไฟล์ migration หนึ่งไฟล์ โดย version คือชื่อไฟล์
+type migration struct {
+	version    string
+	statements []string
+}
+
+// loadMigrations อ่านไฟล์ migration ทั้งหมดเรียงตามชื่อไฟล์
+func loadMigrations() ([]migration, error) {
+	paths, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var migrations []migration
+	for _, path := range paths {
+		data, err := migrationFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{
+			version:    strings.TrimPrefix(path, "migrations/"),
+			statements: splitStatements(string(data)),
+		})
+	}
+	return migrations, nil
+}
+
+// splitStatements แยกไฟล์ SQL เป็นคำสั่งตาม ; โดยตัดบรรทัด comment และคำสั่งว่างออก
+// ไฟล์ migration ต้องไม่มี ; อยู่ในข้อความหรือ comment
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// Migrate รัน migration ที่ยังไม่ได้รันตามลำดับ
+//
+// ระหว่างรันจะถือ advisory lock ของ MySQL (GET_LOCK) ไว้ instance อื่นที่เริ่มพร้อมกันจะรอ lock
+// แล้วพบว่า migration ถูกรันไปแล้วจึงทำงานต่อได้เลย ถ้ารอเกิน timeout จะคืน error ทันที
+// lock ผูกกับ connection จึงถูกปล่อยเองเมื่อ instance ที่ถือ lock ตายไป
+// เมื่อ ctx ถูกยกเลิก query ที่ค้างอยู่จะถูกยกเลิกและ connection ที่ถือ lock จะถูกปิด
+func Migrate(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("migrate: timed out after %s waiting for lock %q; another instance may be stuck migrating", timeout, migrationLockName)
+	}
+	// ปล่อย lock ด้วย context ใหม่ เพราะ ctx อาจถูกยกเลิกไปแล้ว ถ้า connection เสียไปแล้ว lock ก็ถูกปล่อยไปด้วย
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(255) NOT NULL PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`)
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		// MySQL commit DDL ทันที จึงบันทึก version หลังจากทุกคำสั่งในไฟล์สำเร็จแล้วเท่านั้น
+		for _, statement := range m.statements {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("migrate: %s: %w", m.version, err)
+			}
+		}
+		if _, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", m.version); err != nil {
+			return err
+		}
+		log.Printf("migrate: applied %s", m.version)
+	}
+	return nil
+}
+
+// appliedMigrations ดึง version ของ migration ที่รันไปแล้ว
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
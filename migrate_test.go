@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment line; with a semicolon
+CREATE TABLE a (id INT);
+
+  -- indented comment
+ALTER TABLE a
+    ADD COLUMN b INT;
+;
+`
+	want := []string{"CREATE TABLE a (id INT)", "ALTER TABLE a\n    ADD COLUMN b INT"}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements = %q, want %q", got, want)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	versions := make([]string, len(migrations))
+	for i, m := range migrations {
+		versions[i] = m.version
+		if len(m.statements) == 0 {
+			t.Errorf("%s has no statements", m.version)
+		}
+		number, err := migrationNumber(m.version)
+		if err != nil {
+			t.Errorf("%s: %v", m.version, err)
+		}
+		// เลขของ migration ต้องเรียงต่อกันโดยไม่ข้าม เพราะ schema version คือเลขของ migration ล่าสุด
+		if number != i+1 {
+			t.Errorf("%s has number %d, want %d", m.version, number, i+1)
+		}
+	}
+	if !sort.StringsAreSorted(versions) {
+		t.Errorf("migrations are not sorted: %v", versions)
+	}
+	if last, _ := migrationNumber(versions[len(versions)-1]); last != maxSchemaVersion {
+		t.Errorf("latest migration is %d, but maxSchemaVersion is %d", last, maxSchemaVersion)
+	}
+}
@@ -0,0 +1,6 @@
+-- ตาราง recipe ที่ MySQLStore ใช้งาน
+CREATE TABLE IF NOT EXISTS recipe (
+    name        VARCHAR(255)  NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
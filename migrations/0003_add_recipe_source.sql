@@ -0,0 +1,4 @@
+-- เพิ่มข้อมูลแหล่งที่มาของ recipe (ไม่บังคับ)
+ALTER TABLE recipe
+    ADD COLUMN source_url  VARCHAR(2048) NULL,
+    ADD COLUMN source_name VARCHAR(255)  NULL;
@@ -0,0 +1,5 @@
+-- เพิ่ม ID ของฐานข้อมูลสูตรอาหารภายนอก เพื่อกันการ import ซ้ำ
+ALTER TABLE recipe
+    ADD COLUMN external_id     VARCHAR(100) NULL,
+    ADD COLUMN external_source VARCHAR(50)  NULL,
+    ADD UNIQUE KEY uq_recipe_external (external_id, external_source);
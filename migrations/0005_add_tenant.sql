@@ -0,0 +1,10 @@
+-- แยกข้อมูล recipe ตาม tenant สำหรับการใช้งานแบบ SaaS
+ALTER TABLE recipe
+    ADD COLUMN tenant_id VARCHAR(100) NOT NULL DEFAULT 'default' FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (tenant_id, name),
+    DROP INDEX uq_recipe_external,
+    ADD UNIQUE KEY uq_recipe_external (tenant_id, external_id, external_source);
+
+ALTER TABLE users
+    ADD COLUMN tenant_id VARCHAR(100) NOT NULL DEFAULT 'default';
@@ -0,0 +1,3 @@
+-- รองรับคำอธิบายขนาดใหญ่ (สูงสุด 16MB)
+ALTER TABLE recipe
+    MODIFY COLUMN description MEDIUMTEXT NOT NULL;
@@ -0,0 +1,3 @@
+-- บทบาทของผู้ใช้ (user หรือ admin)
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
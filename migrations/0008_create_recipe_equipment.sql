@@ -0,0 +1,10 @@
+-- อุปกรณ์ที่ต้องใช้ในแต่ละสูตรอาหาร
+CREATE TABLE IF NOT EXISTS recipe_equipment (
+    tenant_id      VARCHAR(100) NOT NULL,
+    recipe_name    VARCHAR(255) NOT NULL,
+    equipment_name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (tenant_id, recipe_name, equipment_name),
+    KEY idx_recipe_equipment_name (tenant_id, equipment_name),
+    CONSTRAINT fk_recipe_equipment_recipe FOREIGN KEY (tenant_id, recipe_name)
+        REFERENCES recipe (tenant_id, name) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
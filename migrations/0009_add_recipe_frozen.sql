@@ -0,0 +1,4 @@
+-- สถานะล็อก Recipe ไม่ให้แก้ไขหรือลบ พร้อมเหตุผล
+ALTER TABLE recipe
+    ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN frozen_reason VARCHAR(500) NULL;
@@ -0,0 +1,3 @@
+-- เวลาที่สร้าง Recipe ใช้สำหรับเรียงลำดับรายการ
+ALTER TABLE recipe
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
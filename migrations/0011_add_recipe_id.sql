@@ -0,0 +1,7 @@
+-- ใช้ id ตัวเลขเป็น primary key แทนชื่อ เพื่อให้เปลี่ยนชื่อ Recipe ได้
+-- ชื่อยังต้องไม่ซ้ำกันภายใน tenant และ recipe_equipment ยังอ้างอิงด้วยชื่อ (ON UPDATE CASCADE)
+ALTER TABLE recipe
+    ADD COLUMN id BIGINT NOT NULL AUTO_INCREMENT FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (id),
+    ADD UNIQUE KEY uq_recipe_name (tenant_id, name);
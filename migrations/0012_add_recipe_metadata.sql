@@ -0,0 +1,3 @@
+-- ฟิลด์เพิ่มเติมที่แต่ละ deployment กำหนดเองผ่าน METADATA_SCHEMA_FILE
+ALTER TABLE recipe
+    ADD COLUMN metadata JSON NULL;
@@ -0,0 +1,4 @@
+-- เวลาที่แก้ไข Recipe ครั้งล่าสุด แถวเดิมเริ่มจากเวลาที่สร้าง
+ALTER TABLE recipe
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+UPDATE recipe SET updated_at = created_at;
@@ -0,0 +1,13 @@
+-- วัตถุดิบของแต่ละสูตรอาหาร ลบตามเมื่อ Recipe ถูกลบ
+CREATE TABLE IF NOT EXISTS ingredient (
+    id         BIGINT       NOT NULL AUTO_INCREMENT,
+    tenant_id  VARCHAR(100) NOT NULL,
+    recipe_id  BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    quantity   DOUBLE       NOT NULL,
+    unit       VARCHAR(32)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_ingredient_recipe (recipe_id, id),
+    CONSTRAINT fk_ingredient_recipe FOREIGN KEY (recipe_id)
+        REFERENCES recipe (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
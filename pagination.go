@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ขนาดหน้าเริ่มต้นและขนาดหน้าสูงสุดของรายการ
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// sortableColumns คือคอลัมน์ที่ใช้เรียงรายการได้ โดย key คือค่าใน ?sort=
+// ค่าที่ไม่อยู่ในนี้จะไม่ถูกนำไปต่อใน SQL
+var sortableColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// ListOptions คือการแบ่งหน้าและการเรียงลำดับของรายการ Recipe
+type ListOptions struct {
+	Limit  int
+	Offset int
+
+	// SortBy ต้องเป็น key ของ sortableColumns
+	SortBy string
+
+	// Order คือ asc หรือ desc
+	Order string
+}
+
+// orderBy คืน ORDER BY clause ของ ListOptions โดยใช้ id เป็นตัวตัดสินเมื่อค่าที่ใช้เรียงเท่ากัน
+// เพื่อให้ลำดับคงที่ระหว่างหน้า
+func (o ListOptions) orderBy() string {
+	column, ok := sortableColumns[o.SortBy]
+	if !ok {
+		column = "id"
+	}
+	direction := "ASC"
+	if o.Order == "desc" {
+		direction = "DESC"
+	}
+
+	clause := " ORDER BY " + column + " " + direction
+	if column != "id" {
+		clause += ", id " + direction
+	}
+	return clause
+}
+
+// parseListOptions อ่าน ListOptions จาก ?limit= ?offset= ?sort= และ ?order=
+func parseListOptions(c *gin.Context) (ListOptions, error) {
+	opts := ListOptions{Limit: defaultPageSize, SortBy: "id", Order: "asc"}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return opts, fmt.Errorf("limit must be an integer between 1 and %d", maxPageSize)
+		}
+		opts.Limit = limit
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return opts, errors.New("offset must be a non-negative integer")
+		}
+		opts.Offset = offset
+	}
+	if v := c.Query("sort"); v != "" {
+		if _, ok := sortableColumns[v]; !ok {
+			return opts, errors.New("sort must be one of id, name, created_at, updated_at")
+		}
+		opts.SortBy = v
+	}
+	if v := c.Query("order"); v != "" {
+		if v != "asc" && v != "desc" {
+			return opts, errors.New("order must be asc or desc")
+		}
+		opts.Order = v
+	}
+	return opts, nil
+}
+
+// PageLinks คือลิงก์ไปยังหน้าถัดไปและหน้าก่อนหน้า ลิงก์ที่ไม่มีจะไม่ถูกส่ง
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// RecipePage คือรายการ Recipe หนึ่งหน้าพร้อมจำนวนทั้งหมดที่ตรงกับเงื่อนไข
+type RecipePage struct {
+	Recipes []Recipe  `json:"recipes"`
+	Total   int       `json:"total"`
+	Limit   int       `json:"limit"`
+	Offset  int       `json:"offset"`
+	Links   PageLinks `json:"links"`
+}
+
+// newRecipePage สร้าง RecipePage พร้อมลิงก์ที่คงเงื่อนไขอื่นใน query string ของ request ไว้
+func newRecipePage(c *gin.Context, recipes []Recipe, total int, opts ListOptions) RecipePage {
+	page := RecipePage{Recipes: recipes, Total: total, Limit: opts.Limit, Offset: opts.Offset}
+	if opts.Offset+opts.Limit < total {
+		page.Links.Next = pageURL(c, opts.Offset+opts.Limit, opts.Limit)
+	}
+	if opts.Offset > 0 {
+		prev := opts.Offset - opts.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		page.Links.Prev = pageURL(c, prev, opts.Limit)
+	}
+	return page
+}
+
+// pageURL คืน URL ของ request ปัจจุบันที่เปลี่ยน offset และ limit แล้ว
+func pageURL(c *gin.Context, offset, limit int) string {
+	u := *c.Request.URL
+	query := u.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// patchRecipe ส่ง PATCH /recipes/1 และคืน Recipe ที่แก้ไขแล้ว
+func (s *testServer) patchRecipe(t *testing.T, body string) Recipe {
+	t.Helper()
+	w := s.do(http.MethodPatch, "/recipes/1", body, writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	return recipe
+}
+
+func TestPatchDescriptionKeepsName(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"old","equipment":["wok"]}`)
+
+	patched := server.patchRecipe(t, `{"description":"new"}`)
+	if patched.Name != "Pad Thai" || patched.Description != "new" {
+		t.Errorf("patched = %q / %q", patched.Name, patched.Description)
+	}
+	if len(patched.Equipment) != 1 || patched.Equipment[0] != "wok" {
+		t.Errorf("equipment = %v, want it untouched", patched.Equipment)
+	}
+
+	// ค่าที่บันทึกตรงกับที่ PATCH ตอบกลับ
+	w := server.do(http.MethodGet, "/recipes/1", "")
+	var stored Recipe
+	decodeEnvelope(t, w, &stored)
+	if stored.Name != "Pad Thai" || stored.Description != "new" {
+		t.Errorf("stored = %q / %q", stored.Name, stored.Description)
+	}
+}
+
+func TestPatchNameKeepsDescription(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"noodles","equipment":[]}`)
+
+	patched := server.patchRecipe(t, `{"name":"  Pad See Ew "}`)
+	if patched.Name != "Pad See Ew" || patched.Description != "noodles" {
+		t.Errorf("patched = %q / %q", patched.Name, patched.Description)
+	}
+}
+
+func TestPatchRecipeErrors(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	server.createRecipe(t, `{"name":"Tom Yum","description":"d","equipment":[]}`)
+
+	cases := []struct {
+		name, path, body string
+		status           int
+		code, field      string
+	}{
+		{"empty object", "/recipes/1", `{}`, http.StatusBadRequest, codeBadRequest, ""},
+		{"malformed", "/recipes/1", `{"name":`, http.StatusBadRequest, codeBadRequest, ""},
+		{"unknown field", "/recipes/1", `{"descripton":"typo"}`, http.StatusUnprocessableEntity, codeValidationFailed, "descripton"},
+		{"blank name", "/recipes/1", `{"name":"  "}`, http.StatusUnprocessableEntity, codeValidationFailed, "name"},
+		{"missing recipe", "/recipes/99", `{"description":"new"}`, http.StatusNotFound, codeNotFound, ""},
+		{"duplicate name", "/recipes/1", `{"name":"Tom Yum"}`, http.StatusConflict, codeConflict, "name"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(http.MethodPatch, tc.path, tc.body, writeHeaders...)
+			expectStatus(t, w, tc.status)
+			envelope := decodeEnvelope(t, w, nil)
+			if len(envelope.Errors) != 1 || envelope.Errors[0].Code != tc.code || envelope.Errors[0].Field != tc.field {
+				t.Errorf("errors = %+v, want %s on %q", envelope.Errors, tc.code, tc.field)
+			}
+		})
+	}
+
+	// PATCH ที่ไม่ผ่านไม่เปลี่ยนข้อมูลเดิม
+	w := server.do(http.MethodGet, "/recipes/1", "")
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if recipe.Name != "Pad Thai" || recipe.Description != "d" {
+		t.Errorf("recipe = %q / %q after rejected patches", recipe.Name, recipe.Description)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// printTemplates คือ template ของหน้าพิมพ์สูตรอาหาร ซึ่งโหลดครั้งเดียวตอนเริ่มโปรแกรม
+var printTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// PrintPreview คือ handler สำหรับแสดงสูตรอาหารเป็นหน้า HTML ที่พร้อมพิมพ์
+func (h *RecipesHandler) PrintPreview(c *gin.Context) {
+	// ดึงพารามิเตอร์ URL
+	id, ok := recipeID(c)
+	if !ok {
+		return
+	}
+
+	// ดึงข้อมูลสูตรอาหารจาก store ด้วย ID
+	recipe, err := h.store.Get(c.Request.Context(), tenantID(c), id)
+	if err != nil {
+		respondStoreErr(c, err)
+		return
+	}
+
+	// render ลง buffer ก่อน เพื่อให้ตอบ 500 ได้ถ้า template ผิดพลาด
+	var buf bytes.Buffer
+	if err := printTemplates.ExecuteTemplate(&buf, "recipe-print.html", redactRecipe(recipe, visibilityPublic)); err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPrintPreview(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Fish & Chips","description":"crispy","equipment":["fryer"],"external_id":"secret-42","external_source":"partner"}`)
+	w := server.do(http.MethodPost, "/recipes/1/ingredients", `{"name":"potato","quantity":500,"unit":"grams"}`, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+
+	w = server.do(http.MethodGet, "/recipes/1/print-preview", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"<h1>Fish &amp; Chips</h1>",
+		"<p>crispy</p>",
+		`<a href="/recipes/1">`,
+		"<li>500 g potato</li>",
+		"<li>fryer</li>",
+		"@media print",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "secret-42") {
+		t.Errorf("body leaks a staff-only field:\n%s", body)
+	}
+}
+
+func TestPrintPreviewErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		store  recipeStore
+		path   string
+		status int
+	}{
+		{"missing recipe", NewMemoryStore(), "/recipes/999/print-preview", http.StatusNotFound},
+		{"invalid id", NewMemoryStore(), "/recipes/abc/print-preview", http.StatusBadRequest},
+		{"store failure", failingStore{recipeStore: NewMemoryStore(), err: &StoreError{Op: "Get", Err: errors.New("connection refused")}}, "/recipes/1/print-preview", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServerWithStore(t, tc.store, nil)
+			w := server.do(http.MethodGet, tc.path, "")
+			expectStatus(t, w, tc.status)
+			decodeEnvelope(t, w, nil)
+		})
+	}
+}
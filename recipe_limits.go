@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ค่าเริ่มต้นของขนาดคำอธิบาย นับเป็นจำนวนตัวอักษร (rune) ไม่ใช่ byte
+const (
+	defaultMaxDescriptionRunes = 10000
+	defaultSummaryRunes        = 200
+)
+
+// RecipeLimits คือขีดจำกัดขนาดของข้อมูล Recipe
+type RecipeLimits struct {
+	// MaxDescriptionRunes คือความยาวสูงสุดของคำอธิบายที่รับได้
+	MaxDescriptionRunes int
+	// SummaryRunes คือความยาวของคำอธิบายที่แสดงในรายการ
+	SummaryRunes int
+}
+
+// descriptionFits ตรวจสอบว่าคำอธิบายยาวไม่เกินขีดจำกัด
+func (l RecipeLimits) descriptionFits(description string) bool {
+	return utf8.RuneCountInString(description) <= l.MaxDescriptionRunes
+}
+
+// descriptionTooLong คืนข้อความ error เมื่อคำอธิบายยาวเกินขีดจำกัด
+func (l RecipeLimits) descriptionTooLong() string {
+	return fmt.Sprintf("description must be at most %d characters", l.MaxDescriptionRunes)
+}
+
+// truncateRunes ตัดสตริงให้เหลือไม่เกิน n ตัวอักษรแล้วต่อท้ายด้วย "…"
+// การตัดนับเป็น rune จึงไม่ตัดกลางตัวอักษรหลาย byte เช่นภาษาไทย
+// และถ้าจุดตัดตกอยู่ที่สระหรือวรรณยุกต์ที่ซ้อนอยู่บนหรือล่างพยัญชนะ (เช่น ี ่ ุ)
+// จะถอยไปตัดก่อนพยัญชนะตัวนั้น เพื่อไม่ให้เหลือพยัญชนะที่ขาดสระหรือวรรณยุกต์ของมัน
+func truncateRunes(s string, n int) (string, bool) {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+
+	cut, count := 0, 0
+	for i := range s {
+		if count == n {
+			cut = i
+			break
+		}
+		count++
+	}
+	// ถอยทีละตัวจนจุดตัดอยู่ที่พยัญชนะฐาน พยัญชนะนั้นจึงถูกตัดออกไปพร้อมเครื่องหมายทั้งหมดของมัน
+	for cut > 0 {
+		if r, _ := utf8.DecodeRuneInString(s[cut:]); !unicode.Is(unicode.Mn, r) {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(s[:cut])
+		cut -= size
+	}
+	return s[:cut] + "…", true
+}
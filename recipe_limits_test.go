@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	// ผัดไทยกุ้งสด มี 12 rune โดย ั ุ และ ้ เป็นสระและวรรณยุกต์ที่ซ้อนบนหรือล่างพยัญชนะ
+	const thai = "ผัดไทยกุ้งสด"
+	cases := []struct {
+		s         string
+		n         int
+		want      string
+		truncated bool
+	}{
+		{thai, 13, thai, false},
+		{thai, 12, thai, false},
+		{thai, 11, "ผัดไทยกุ้งส…", true},
+		{thai, 9, "ผัดไทยกุ้…", true},
+		// จุดตัดตกที่ ้ หรือ ุ จึงตัด กุ้ ออกทั้งพยางค์แทนที่จะเหลือ ก หรือ กุ
+		{thai, 8, "ผัดไทย…", true},
+		{thai, 7, "ผัดไทย…", true},
+		{thai, 2, "ผั…", true},
+		{thai, 1, "…", true},
+		{"สวัสดี", 5, "สวัส…", true},
+		{"สวัสดี", 6, "สวัสดี", false},
+		{"", 3, "", false},
+		{thai, 0, thai, false},
+	}
+	for _, tc := range cases {
+		got, truncated := truncateRunes(tc.s, tc.n)
+		if got != tc.want || truncated != tc.truncated {
+			t.Errorf("truncateRunes(%q, %d) = %q, %v; want %q, %v", tc.s, tc.n, got, truncated, tc.want, tc.truncated)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateRunes(%q, %d) = %q is not valid UTF-8", tc.s, tc.n, got)
+		}
+		if body := strings.TrimSuffix(got, "…"); utf8.RuneCountInString(body) > tc.n && tc.truncated {
+			t.Errorf("truncateRunes(%q, %d) kept %d characters", tc.s, tc.n, utf8.RuneCountInString(body))
+		}
+	}
+}
+
+func TestListSummarizesDescriptions(t *testing.T) {
+	const summary = 10
+	server := newTestServer(t, func(cfg *Config) {
+		cfg.Limits.SummaryRunes = summary
+	})
+	descriptions := []string{
+		strings.Repeat("ก", summary-1),
+		strings.Repeat("ก", summary),
+		strings.Repeat("ก", summary+1),
+		strings.Repeat("ก", summary-1) + "ี่ยว",
+	}
+	for i, description := range descriptions {
+		server.createRecipe(t, `{"name":"recipe `+string(rune('a'+i))+`","description":"`+description+`","equipment":[]}`)
+	}
+
+	want := []struct {
+		description string
+		truncated   bool
+	}{
+		{descriptions[0], false},
+		{descriptions[1], false},
+		{strings.Repeat("ก", summary) + "…", true},
+		{strings.Repeat("ก", summary-2) + "…", true},
+	}
+	page := server.listRecipes(t, "/recipes")
+	if len(page.Recipes) != len(want) {
+		t.Fatalf("listed %d recipes, want %d", len(page.Recipes), len(want))
+	}
+	for i, recipe := range page.Recipes {
+		if recipe.Description != want[i].description || recipe.Truncated != want[i].truncated {
+			t.Errorf("recipe %d = %q truncated %v, want %q truncated %v", i, recipe.Description, recipe.Truncated, want[i].description, want[i].truncated)
+		}
+	}
+
+	// expand=description และการอ่านทีละรายการได้คำอธิบายเต็มเสมอ
+	for i, recipe := range server.listRecipes(t, "/recipes?expand=description").Recipes {
+		if recipe.Description != descriptions[i] || recipe.Truncated {
+			t.Errorf("expanded recipe %d = %q truncated %v", i, recipe.Description, recipe.Truncated)
+		}
+	}
+	w := server.do(http.MethodGet, "/recipes/3", "")
+	expectStatus(t, w, http.StatusOK)
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if recipe.Description != descriptions[2] || recipe.Truncated {
+		t.Errorf("GET /recipes/3 = %q truncated %v, want the full description", recipe.Description, recipe.Truncated)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateRecipe(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	w := server.do(http.MethodPost, "/recipes", `{"name":"Pad Thai","description":"Stir-fried noodles","equipment":["wok"]}`, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+	var created Recipe
+	decodeEnvelope(t, w, &created)
+	if created.ID == 0 || created.Name != "Pad Thai" || created.Description != "Stir-fried noodles" {
+		t.Errorf("created = %+v", created)
+	}
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Errorf("server-generated timestamps missing: %+v", created)
+	}
+	if got, want := w.Header().Get("Location"), "/recipes/1"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+
+	// Location ต้องชี้ไปยังสูตรอาหารที่เพิ่งสร้าง
+	w = server.do(http.MethodGet, w.Header().Get("Location"), "")
+	expectStatus(t, w, http.StatusOK)
+	var stored Recipe
+	decodeEnvelope(t, w, &stored)
+	if stored.Name != created.Name {
+		t.Errorf("Location resolves to %q, want %q", stored.Name, created.Name)
+	}
+}
+
+func TestCreateRecipeDuplicateName(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodPost, "/recipes", `{"name":"Pad Thai","description":"other","equipment":[]}`, writeHeaders...)
+	expectStatus(t, w, http.StatusConflict)
+	envelope := decodeEnvelope(t, w, nil)
+	if len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeConflict || envelope.Errors[0].Field != "name" {
+		t.Errorf("errors = %+v, want one conflict on name", envelope.Errors)
+	}
+	if page := server.listRecipes(t, "/recipes"); page.Total != 1 {
+		t.Errorf("total = %d after a rejected duplicate, want 1", page.Total)
+	}
+}
+
+func TestCreateRecipeMalformedJSON(t *testing.T) {
+	server := newTestServer(t, nil)
+	for _, body := range []string{`{"name":`, `not json`, `["Pad Thai"]`} {
+		w := server.do(http.MethodPost, "/recipes", body, writeHeaders...)
+		expectStatus(t, w, http.StatusBadRequest)
+		if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeBadRequest {
+			t.Errorf("body %q: errors = %+v", body, envelope.Errors)
+		}
+	}
+	if page := server.listRecipes(t, "/recipes"); page.Total != 0 {
+		t.Errorf("total = %d after malformed requests, want 0", page.Total)
+	}
+}
+
+func TestRecipeIDMustBeNumeric(t *testing.T) {
+	server := newTestServer(t, nil)
+	body := `{"name":"Pad Thai","description":"d","equipment":[]}`
+	for _, id := range []string{"abc", "0", "-1", "1.5", "Pad%20Thai"} {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			w := server.do(method, "/recipes/"+id, body, writeHeaders...)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s /recipes/%s status = %d, want %d", method, id, w.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+func TestMissingRecipeIsNotFound(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	body := `{"name":"Other","description":"d","equipment":[]}`
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		w := server.do(method, "/recipes/2", body, writeHeaders...)
+		expectStatus(t, w, http.StatusNotFound)
+		if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeNotFound {
+			t.Errorf("%s errors = %+v", method, envelope.Errors)
+		}
+	}
+}
+
+func TestRenameRecipeKeepsID(t *testing.T) {
+	server := newTestServer(t, nil)
+	created := server.createRecipe(t, `{"name":"ผัดไทย","description":"d","equipment":["wok"]}`)
+
+	w := server.do(http.MethodPut, "/recipes/1", `{"name":"Pad Thai","description":"d","equipment":["wok"]}`, writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	var renamed Recipe
+	decodeEnvelope(t, w, &renamed)
+	if renamed.ID != created.ID || renamed.Name != "Pad Thai" {
+		t.Errorf("renamed = %+v, want id %d named Pad Thai", renamed, created.ID)
+	}
+
+	// ชื่อเดิมว่างแล้ว จึงใช้กับสูตรอาหารใหม่ได้
+	if recreated := server.createRecipe(t, `{"name":"ผัดไทย","description":"d","equipment":[]}`); recreated.ID == created.ID {
+		t.Errorf("new recipe reused id %d", created.ID)
+	}
+}
+
+func TestDeleteRecipe(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodDelete, "/recipes/1", "", writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	w = server.do(http.MethodGet, "/recipes/1", "")
+	expectStatus(t, w, http.StatusNotFound)
+	w = server.do(http.MethodDelete, "/recipes/1", "", writeHeaders...)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+func TestListRecipesOrderedByID(t *testing.T) {
+	server := newTestServer(t, nil)
+	names := []string{"Tom Yum", "Green Curry", "Som Tam"}
+	for _, name := range names {
+		server.createRecipe(t, `{"name":"`+name+`","description":"d","equipment":[]}`)
+	}
+
+	page := server.listRecipes(t, "/recipes")
+	if page.Total != len(names) || len(page.Recipes) != len(names) {
+		t.Fatalf("page = %+v", page)
+	}
+	for i, recipe := range page.Recipes {
+		if recipe.ID != int64(i+1) || recipe.Name != names[i] {
+			t.Errorf("recipes[%d] = %d %q, want %d %q", i, recipe.ID, recipe.Name, i+1, names[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// visibility คือระดับของผู้ที่มองเห็นฟิลด์ได้ ค่ามากกว่าเห็นได้มากกว่า
+type visibility int
+
+// ระดับการมองเห็นของฟิลด์
+const (
+	visibilityPublic visibility = iota
+	visibilityStaff
+	visibilityAdmin
+)
+
+// visibilityLevels แปลงค่าใน tag visibility เป็นระดับการมองเห็น
+// ฟิลด์ที่ไม่มี tag หรือมีค่าที่ไม่รู้จักจะถือว่าเป็น admin เพื่อไม่ให้ฟิลด์ใหม่หลุดออกไปโดยไม่ตั้งใจ
+var visibilityLevels = map[string]visibility{
+	"public": visibilityPublic,
+	"staff":  visibilityStaff,
+	"admin":  visibilityAdmin,
+}
+
+// callerVisibility คืนระดับการมองเห็นของผู้เรียกตาม role ใน JWT
+func callerVisibility(c *gin.Context) visibility {
+	claims := currentClaims(c)
+	if claims == nil {
+		return visibilityPublic
+	}
+
+	switch claims.Role {
+	case roleAdmin:
+		return visibilityAdmin
+	case roleEditor:
+		return visibilityStaff
+	}
+	return visibilityPublic
+}
+
+// redact ลบฟิลด์ที่ผู้เรียกไม่มีสิทธิ์เห็นออกจาก Recipe และรายการ Recipe
+// ข้อมูลประเภทอื่นที่ไม่มี Recipe อยู่ภายในจะถูกคืนค่าตามเดิม ส่วนประเภทที่มี Recipe แต่ไม่มีวิธี redact
+// จะคืน error แทนการส่งข้อมูลออกไปทั้งหมด เพื่อไม่ให้ฟิลด์ที่ซ่อนไว้หลุดออกไปกับ response ประเภทใหม่
+func redact(data interface{}, level visibility) (interface{}, error) {
+	switch v := data.(type) {
+	case Recipe:
+		return redactRecipe(v, level), nil
+	case map[string]Recipe:
+		redacted := make(map[string]Recipe, len(v))
+		for name, recipe := range v {
+			redacted[name] = redactRecipe(recipe, level)
+		}
+		return redacted, nil
+	case []Recipe:
+		redacted := make([]Recipe, len(v))
+		for i, recipe := range v {
+			redacted[i] = redactRecipe(recipe, level)
+		}
+		return redacted, nil
+	case RecipePage:
+		recipes, _ := redact(v.Recipes, level)
+		v.Recipes = recipes.([]Recipe)
+		return v, nil
+	case FetchRecipesResponse:
+		recipes, _ := redact(v.Recipes, level)
+		v.Recipes = recipes.(map[string]Recipe)
+		return v, nil
+	}
+
+	if containsRecipe(reflect.ValueOf(data)) {
+		return nil, fmt.Errorf("redact: no redaction rule for %T which contains recipes", data)
+	}
+	return data, nil
+}
+
+// containsRecipe ตรวจสอบว่าค่าที่ได้รับมี Recipe อยู่ภายในหรือไม่ รวมถึงค่าที่อยู่ใน interface เช่น gin.H
+func containsRecipe(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return !value.IsNil() && containsRecipe(value.Elem())
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(Recipe{}) {
+			return true
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if containsRecipe(value.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if containsRecipe(value.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if containsRecipe(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactRecipe คืนสำเนาของ recipe ที่ฟิลด์ซึ่งต้องการระดับสูงกว่า level ถูกตั้งเป็นค่าว่าง
+func redactRecipe(recipe Recipe, level visibility) Recipe {
+	value := reflect.ValueOf(&recipe).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if fieldVisibility(value.Type().Field(i)) > level {
+			value.Field(i).Set(reflect.Zero(value.Field(i).Type()))
+		}
+	}
+	return recipe
+}
+
+// fieldVisibility อ่านระดับการมองเห็นจาก tag visibility ของฟิลด์
+func fieldVisibility(field reflect.StructField) visibility {
+	level, ok := visibilityLevels[field.Tag.Get("visibility")]
+	if !ok {
+		return visibilityAdmin
+	}
+	return level
+}
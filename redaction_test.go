@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// filledRecipe คืน Recipe ที่ทุกฟิลด์มีค่า เพื่อให้ตรวจได้ว่าฟิลด์ใดถูกลบออก
+func filledRecipe() Recipe {
+	return Recipe{
+		ID:             1,
+		Name:           "Pad Thai",
+		Description:    "noodles",
+		SourceURL:      "https://example.com/pad-thai",
+		SourceName:     "Example",
+		ExternalID:     "ext-1",
+		ExternalSource: "partner",
+		Equipment:      []string{"wok"},
+		Ingredients:    []Ingredient{{Name: "noodles", Quantity: 200, Unit: "g"}},
+		Metadata:       map[string]interface{}{"spicy": true},
+		CreatedAt:      time.Unix(1, 0),
+		UpdatedAt:      time.Unix(2, 0),
+		Truncated:      true,
+	}
+}
+
+func TestRecipeFieldsHaveKnownVisibility(t *testing.T) {
+	recipeType := reflect.TypeOf(Recipe{})
+	for i := 0; i < recipeType.NumField(); i++ {
+		field := recipeType.Field(i)
+		tag, ok := field.Tag.Lookup("visibility")
+		if !ok {
+			t.Errorf("Recipe.%s has no visibility tag", field.Name)
+			continue
+		}
+		if _, ok := visibilityLevels[tag]; !ok {
+			t.Errorf("Recipe.%s has unknown visibility %q", field.Name, tag)
+		}
+	}
+}
+
+func TestRedactRecipeEnumeratesEveryField(t *testing.T) {
+	recipe := filledRecipe()
+	recipeValue := reflect.ValueOf(recipe)
+	for i := 0; i < recipeValue.NumField(); i++ {
+		if recipeValue.Field(i).IsZero() {
+			t.Fatalf("filledRecipe leaves Recipe.%s empty; add a value for the new field", recipeValue.Type().Field(i).Name)
+		}
+	}
+
+	levels := []struct {
+		name  string
+		level visibility
+	}{
+		{"public", visibilityPublic},
+		{"staff", visibilityStaff},
+		{"admin", visibilityAdmin},
+	}
+	for _, tc := range levels {
+		t.Run(tc.name, func(t *testing.T) {
+			redacted := reflect.ValueOf(redactRecipe(recipe, tc.level))
+			for i := 0; i < redacted.NumField(); i++ {
+				field := redacted.Type().Field(i)
+				hidden := fieldVisibility(field) > tc.level
+				if redacted.Field(i).IsZero() != hidden {
+					t.Errorf("Recipe.%s (visibility %q): hidden = %v, want %v",
+						field.Name, field.Tag.Get("visibility"), redacted.Field(i).IsZero(), hidden)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactWrappers(t *testing.T) {
+	recipe := filledRecipe()
+	cases := []struct {
+		name string
+		data interface{}
+		get  func(interface{}) Recipe
+	}{
+		{"recipe", recipe, func(v interface{}) Recipe { return v.(Recipe) }},
+		{"slice", []Recipe{recipe}, func(v interface{}) Recipe { return v.([]Recipe)[0] }},
+		{"map", map[string]Recipe{"pad thai": recipe}, func(v interface{}) Recipe { return v.(map[string]Recipe)["pad thai"] }},
+		{"page", RecipePage{Recipes: []Recipe{recipe}}, func(v interface{}) Recipe { return v.(RecipePage).Recipes[0] }},
+		{"fetch", FetchRecipesResponse{Recipes: map[string]Recipe{"pad thai": recipe}}, func(v interface{}) Recipe {
+			return v.(FetchRecipesResponse).Recipes["pad thai"]
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			redacted, err := redact(tc.data, visibilityPublic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.get(redacted); got.ExternalID != "" || got.ExternalSource != "" {
+				t.Errorf("staff fields leaked: %+v", got)
+			}
+		})
+	}
+}
+
+func TestRedactFailsClosedForUnknownRecipeTypes(t *testing.T) {
+	cases := []struct {
+		name string
+		data interface{}
+	}{
+		{"pointer", &Recipe{ExternalID: "ext-1"}},
+		{"gin.H", gin.H{"recipe": filledRecipe()}},
+		{"struct", struct{ Recipes []Recipe }{Recipes: []Recipe{filledRecipe()}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := redact(tc.data, visibilityPublic); err == nil {
+				t.Fatal("expected an error for a type without a redaction rule")
+			}
+		})
+	}
+
+	// ข้อมูลที่ไม่มี Recipe อยู่ภายในผ่านไปได้ตามเดิม
+	data := gin.H{"username": "alice"}
+	redacted, err := redact(data, visibilityPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(redacted, data) {
+		t.Errorf("redact(%v) = %v", data, redacted)
+	}
+}
+
+func TestExternalIDHiddenFromPublicCallers(t *testing.T) {
+	server := newTestServer(t, nil)
+	body := `{"name":"Pad Thai","description":"d","equipment":[],"external_id":"secret-42","external_source":"partner"}`
+	w := server.do(http.MethodPost, "/recipes", body, apiKeyHeader, testAPIKey)
+	expectStatus(t, w, http.StatusCreated)
+
+	cases := []struct {
+		name    string
+		headers []string
+		visible bool
+	}{
+		{"anonymous", nil, false},
+		{"user", []string{"Authorization", "Bearer " + testToken(t, "user")}, false},
+		{"editor", []string{"Authorization", "Bearer " + testToken(t, roleEditor)}, true},
+		{"admin", []string{"Authorization", "Bearer " + testToken(t, roleAdmin)}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(http.MethodGet, "/recipes/1", "", tc.headers...)
+			expectStatus(t, w, http.StatusOK)
+			var recipe Recipe
+			decodeEnvelope(t, w, &recipe)
+			if visible := recipe.ExternalID == "secret-42"; visible != tc.visible {
+				t.Errorf("external_id visible = %v, want %v", visible, tc.visible)
+			}
+		})
+	}
+
+	// 409 ของ external_id ที่ซ้ำต้องไม่ส่งค่าที่ซ้ำกลับไป
+	body = `{"name":"Other","description":"d","equipment":[],"external_id":"secret-42","external_source":"partner"}`
+	w = server.do(http.MethodPost, "/recipes", body, apiKeyHeader, testAPIKey)
+	expectStatus(t, w, http.StatusConflict)
+	if strings.Contains(w.Body.String(), "secret-42") {
+		t.Errorf("conflict response echoes external_id: %s", w.Body.String())
+	}
+	if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Field != "external_id" {
+		t.Errorf("errors = %+v, want one conflict on external_id", envelope.Errors)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDHeader คือชื่อ header ที่ใช้ส่ง request id
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey คือ key ที่ใช้เก็บ request id ใน Gin context
+const requestIDKey = "request_id"
+
+// รหัส error ที่ใช้ในฟิลด์ code ของ AppError ทุกรหัสต้องอยู่ใน errorCatalog ด้วย
+const (
+	codeBadRequest       = "bad_request"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeConflict         = "conflict"
+	codeLocked           = "locked"
+	codeValidationFailed = "validation_failed"
+	codeInternalError    = "internal_error"
+	codeRequestCancelled = "request_cancelled"
+	codeTimeout          = "timeout"
+	codeReadOnly         = "read_only"
+	codeUnavailable      = "unavailable"
+)
+
+// AppError คือโครงสร้างของ error แต่ละรายการที่ส่งกลับใน envelope
+type AppError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// Meta คือข้อมูลประกอบของทุก response
+type Meta struct {
+	RequestID string    `json:"request_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Envelope คือโครงสร้างที่ห่อทุก response ของ API
+type Envelope struct {
+	Data   interface{} `json:"data"`
+	Meta   Meta        `json:"meta"`
+	Errors []AppError  `json:"errors"`
+}
+
+// RespondSuccess ส่ง response สำเร็จในรูปแบบ envelope หรือ JSON:API ถ้า client ขอ
+func RespondSuccess(c *gin.Context, code int, data interface{}) {
+	data, err := redact(data, callerVisibility(c))
+	if err != nil {
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+		return
+	}
+	if wantsJSONAPI(c) {
+		respondJSONAPI(c, code, data)
+		return
+	}
+	c.JSON(code, Envelope{Data: data, Meta: newMeta(c)})
+}
+
+// RespondError ส่ง response ที่ผิดพลาดในรูปแบบ envelope หรือ JSON:API ถ้า client ขอ
+func RespondError(c *gin.Context, code int, errs []AppError) {
+	errs = sanitizeErrors(errs)
+	if wantsJSONAPI(c) {
+		respondJSONAPIError(c, code, errs)
+		return
+	}
+	c.JSON(code, Envelope{Meta: newMeta(c), Errors: errs})
+}
+
+// respondErr เป็น shortcut ของ RespondError สำหรับ error เพียงรายการเดียว
+// error 500 ที่เกิดหลัง context ของ request หมดเวลาหรือถูกยกเลิกจะตอบเป็น 504 หรือ 499 แทน
+//
+// error ฝั่งเซิร์ฟเวอร์ถูกบันทึกใน c.Errors เพื่อให้ LoggingMiddleware log พร้อม request id
+// ส่วนข้อความของ error 500 อาจมีรายละเอียดของฐานข้อมูล จึงไม่ถูกส่งให้ client
+func respondErr(c *gin.Context, code int, errCode string, message string) {
+	if code >= http.StatusInternalServerError {
+		_ = c.Error(errors.New(message))
+	}
+	if code == http.StatusInternalServerError {
+		message = "internal server error"
+		if status, ctxCode, ok := contextErrorStatus(c); ok {
+			code, errCode = status, ctxCode
+			message = c.Request.Context().Err().Error()
+		}
+	}
+	RespondError(c, code, []AppError{{Code: errCode, Message: message}})
+}
+
+// newMeta สร้าง Meta สำหรับ request ปัจจุบัน
+func newMeta(c *gin.Context) Meta {
+	return Meta{RequestID: requestID(c), Timestamp: time.Now().UTC()}
+}
+
+// requestID คืนค่า request id ของ request ปัจจุบัน
+// ถ้า client ส่ง X-Request-ID ที่ถูกรูปแบบมาจะใช้ค่านั้น ถ้าไม่มีหรือไม่ถูกรูปแบบจะสร้างใหม่
+func requestID(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(requestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = randomID()
+	}
+	c.Set(requestIDKey, id)
+	c.Header(requestIDHeader, id)
+	return id
+}
+
+// randomID สร้าง id แบบสุ่ม 128 bit ในรูป hex ที่ client เดาไม่ได้
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEveryHandlerRespondsWithEnvelope(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"noodles","equipment":["wok"]}`)
+
+	cases := []struct {
+		name               string
+		method, path, body string
+		status             int
+		nullData           bool
+		hasErrors          bool
+	}{
+		{"home", http.MethodGet, "/", "", http.StatusOK, false, false},
+		{"list", http.MethodGet, "/recipes", "", http.StatusOK, false, false},
+		{"create", http.MethodPost, "/recipes", `{"name":"Tom Yum","description":"soup","equipment":[]}`, http.StatusCreated, false, false},
+		{"get", http.MethodGet, "/recipes/1", "", http.StatusOK, false, false},
+		{"update", http.MethodPut, "/recipes/1", `{"name":"Pad Thai","description":"rice noodles","equipment":[]}`, http.StatusOK, false, false},
+		{"delete", http.MethodDelete, "/recipes/2", "", http.StatusOK, true, false},
+		{"not found", http.MethodGet, "/recipes/999", "", http.StatusNotFound, true, true},
+		{"bad request", http.MethodPost, "/recipes", `{`, http.StatusBadRequest, true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(tc.method, tc.path, tc.body, writeHeaders...)
+			expectStatus(t, w, tc.status)
+
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+				t.Fatalf("body is not a JSON object: %v; body %s", err, w.Body.String())
+			}
+			keys := make([]string, 0, len(raw))
+			for key := range raw {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if strings.Join(keys, ",") != "data,errors,meta" {
+				t.Fatalf("top-level keys = %v, want data, errors and meta; body %s", keys, w.Body.String())
+			}
+
+			if isNull := string(raw["data"]) == "null"; isNull != tc.nullData {
+				t.Errorf("data = %s, want null = %v", raw["data"], tc.nullData)
+			}
+			envelope := decodeEnvelope(t, w, nil)
+			if hasErrors := len(envelope.Errors) > 0; hasErrors != tc.hasErrors {
+				t.Errorf("errors = %s, want errors = %v", raw["errors"], tc.hasErrors)
+			}
+			if !tc.hasErrors && string(raw["errors"]) != "null" {
+				t.Errorf("errors = %s, want null", raw["errors"])
+			}
+			if envelope.Meta.RequestID == "" {
+				t.Error("meta.request_id is empty")
+			}
+			if envelope.Meta.RequestID != w.Header().Get(requestIDHeader) {
+				t.Errorf("meta.request_id = %q, header %q", envelope.Meta.RequestID, w.Header().Get(requestIDHeader))
+			}
+			if time.Since(envelope.Meta.Timestamp) > time.Minute {
+				t.Errorf("meta.timestamp = %v", envelope.Meta.Timestamp)
+			}
+		})
+	}
+}
+
+// failingStore คือ MemoryStore ที่ Get คืน err เสมอ ใช้จำลองฐานข้อมูลที่ล้มเหลว
+type failingStore struct {
+	recipeStore
+	err error
+}
+
+func (s failingStore) Get(context.Context, string, int64) (Recipe, error) {
+	return Recipe{}, s.err
+}
+
+func TestErrorEnvelopeHidesInternalMessages(t *testing.T) {
+	storeErr := &StoreError{Op: "Get", Err: errors.New("dial tcp 10.0.0.5:3306: connection refused")}
+	server := newTestServerWithStore(t, failingStore{recipeStore: NewMemoryStore(), err: storeErr}, nil)
+
+	w := server.do(http.MethodGet, "/recipes/1", "")
+	expectStatus(t, w, http.StatusInternalServerError)
+	if strings.Contains(w.Body.String(), "10.0.0.5") {
+		t.Errorf("response leaks the store error: %s", w.Body.String())
+	}
+	envelope := decodeEnvelope(t, w, nil)
+	if len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeInternalError {
+		t.Fatalf("errors = %+v, want one internal_error", envelope.Errors)
+	}
+}
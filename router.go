@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wiratkhamphan/go-rest-demo/units"
+)
+
+// routerDeps คือ dependency ที่ newRouter ใช้สร้าง route ทั้งหมด
+type routerDeps struct {
+	Config    Config
+	Logger    *slog.Logger
+	Registry  *prometheus.Registry
+	Store     recipeStore
+	Users     userStore
+	Lifecycle *Lifecycle
+
+	// ReadOnlyReason ไม่ว่างเมื่อ schema ของฐานข้อมูลไม่ตรงกับ binary และต้องปิดการเขียน
+	ReadOnlyReason string
+}
+
+// newRouter สร้าง Gin router พร้อม middleware และ route ทั้งหมดของเซิร์ฟเวอร์
+// ไฟล์ที่อ่านไม่ได้จะคืน error ที่บอกชื่อตัวแปร environment ของไฟล์นั้น
+func newRouter(deps routerDeps) (*gin.Engine, error) {
+	cfg := deps.Config
+	store := deps.Store
+
+	// สร้าง Gin router ที่บันทึก log ของแต่ละ request เป็น structured log
+	router := gin.New()
+	router.Use(gin.Recovery(), LoggingMiddleware(deps.Logger))
+
+	// metric ของ request ส่งออกที่ /metrics
+	router.Use(NewMetrics(deps.Registry).Middleware())
+
+	// ใส่ header CORS ให้ทุก response รวมถึง error เพื่อให้ browser อ่าน response ได้
+	corsConfig := LoadCORSConfig(cfg)
+	router.Use(CORSMiddleware(corsConfig))
+
+	// ใช้ JWT_SECRET จาก environment ถ้าไม่กำหนดจะสุ่ม secret ใหม่ทุกครั้งที่เริ่มเซิร์ฟเวอร์
+	jwtSecret := []byte(cfg.JWTSecret)
+	if len(jwtSecret) == 0 {
+		log.Println("JWT_SECRET is not set, using a random secret")
+		jwtSecret = randomSecret()
+	}
+	authHandler := NewAuthHandler(deps.Users, jwtSecret)
+
+	// ถ้าไม่ได้เปิด MULTI_TENANT ทุก request จะใช้ข้อมูลของ tenant เริ่มต้นเท่านั้น
+	if !cfg.MultiTenant {
+		store = NewTenantScopedStore(store, defaultTenantID)
+	}
+
+	// schema ของ metadata อ่านจากไฟล์ใน METADATA_SCHEMA_FILE
+	metadataSchema, err := LoadMetadataSchema(cfg.MetadataSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("METADATA_SCHEMA_FILE %q: %w", cfg.MetadataSchemaFile, err)
+	}
+	recipesHandler := NewRecipesHandler(store, cfg.Limits, metadataSchema)
+
+	// ตารางหน่วยเพิ่มเติมอ่านจากไฟล์ใน UNITS_FILE
+	unitTable, err := units.LoadTable(cfg.UnitsFile)
+	if err != nil {
+		return nil, fmt.Errorf("UNITS_FILE %q: %w", cfg.UnitsFile, err)
+	}
+	unitsHandler := NewUnitsHandler(unitTable)
+	ingredientsHandler := NewIngredientsHandler(store, unitTable)
+
+	// ติดตาม request ที่กำลังทำงานเพื่อให้ admin ตรวจสอบและยกเลิกได้
+	requests := NewRequestRegistry()
+	router.Use(requests.Middleware())
+
+	// จำกัดเวลาของแต่ละ request เพื่อไม่ให้ query ที่ค้างทำให้ request ค้างตามไปด้วย
+	router.Use(TimeoutMiddleware(cfg.RequestTimeout))
+
+	// ปิดการเขียนเมื่อ schema ของฐานข้อมูลไม่ตรงกับ binary
+	if deps.ReadOnlyReason != "" {
+		router.Use(ReadOnlyMiddleware(deps.ReadOnlyReason))
+	}
+
+	// ดึง tenant ของผู้เรียกจาก JWT ก่อนเข้าถึงข้อมูล
+	apiKeys, err := LoadAPIKeys(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("API_KEYS_FILE %q: %w", cfg.APIKeysFile, err)
+	}
+	router.Use(TenantMiddleware(jwtSecret, apiKeys))
+
+	// ลงทะเบียน Routes
+	router.GET("/", homePage)
+	healthHandler := NewHealthHandler(store)
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+	router.GET("/healthz/details", deps.Lifecycle.Details)
+	router.GET("/metrics", MetricsHandler(deps.Registry))
+	router.GET("/recipes", recipesHandler.ListRecipes)
+	router.GET("/recipes/:id", recipesHandler.GetRecipe)
+	router.GET("/recipes/:id/print-preview", recipesHandler.PrintPreview)
+	router.GET("/recipes/:id/ingredients", ingredientsHandler.ListIngredients)
+	router.GET("/equipment", recipesHandler.ListEquipment)
+	router.GET("/units", unitsHandler.ListUnits)
+	router.GET("/meta/error-codes", ListErrorCodes)
+	router.POST("/recipes/fetch", recipesHandler.FetchRecipes)
+
+	// Routes ที่เขียนข้อมูลต้องใช้ API key หรือ JWT ของ editor หรือ admin
+	writes := router.Group("", RequireAPIKey(apiKeys))
+	writes.POST("/recipes", recipesHandler.CreateRecipe)
+	writes.POST("/recipes/batch", recipesHandler.AddRecipesBatch)
+	writes.PUT("/recipes/:id", recipesHandler.UpdateRecipe)
+	writes.PATCH("/recipes/:id", recipesHandler.PatchRecipe)
+	writes.DELETE("/recipes/:id", recipesHandler.DeleteRecipe)
+	writes.POST("/recipes/:id/ingredients", ingredientsHandler.AddIngredient)
+	writes.DELETE("/recipes/:id/ingredients/:ingredientId", ingredientsHandler.RemoveIngredient)
+	writes.PATCH("/recipes/batch", RequireRole(roleEditor, roleAdmin), recipesHandler.PatchRecipesBatch)
+
+	// ตอบ CORS preflight ของ route recipes
+	router.OPTIONS("/recipes", corsPreflightHandler(corsConfig, http.MethodGet, http.MethodPost))
+	router.OPTIONS("/recipes/:id", corsPreflightHandler(corsConfig, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete))
+	router.OPTIONS("/recipes/:id/ingredients", corsPreflightHandler(corsConfig, http.MethodGet, http.MethodPost))
+	router.OPTIONS("/recipes/:id/ingredients/:ingredientId", corsPreflightHandler(corsConfig, http.MethodDelete))
+
+	router.POST("/auth/register", authHandler.Register)
+	router.POST("/auth/login", authHandler.Login)
+
+	// Routes สำหรับผู้ดูแลระบบ
+	admin := router.Group("/admin", RequireAdmin())
+	admin.GET("/requests", requests.ListRequests)
+	admin.POST("/requests/:id/cancel", requests.CancelRequest)
+	admin.POST("/impersonate", authHandler.Impersonate)
+	admin.POST("/recipes/:id/freeze", recipesHandler.FreezeRecipe)
+	admin.POST("/recipes/:id/unfreeze", recipesHandler.UnfreezeRecipe)
+
+	return router, nil
+}
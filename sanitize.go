@@ -0,0 +1,45 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxReflectedLength คือความยาวสูงสุด (ตัวอักษร) ของข้อความจากผู้ใช้ที่สะท้อนกลับใน error หรือ log
+const maxReflectedLength = 200
+
+// validRequestID คือรูปแบบของ X-Request-ID ที่ยอมรับจาก client
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// sanitizeText ทำความสะอาดข้อความที่อาจมีข้อมูลจากผู้ใช้ก่อนใส่ใน error หรือ log
+// โดยลบอักขระควบคุม (CR/LF, ANSI escape และอื่น ๆ) และตัดความยาวไม่เกิน maxReflectedLength
+//
+// ข้อความไม่ได้ถูก HTML-escape ที่นี่ เพราะ JSON encoder escape <, > และ & อยู่แล้ว
+// ส่วน response แบบ HTML ใช้ html/template ซึ่ง escape ตาม context ให้เอง
+func sanitizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s, _ = truncateRunes(s, maxReflectedLength)
+	return s
+}
+
+// sanitizeLogText ทำความสะอาดข้อความแบบเดียวกับ sanitizeText แล้ว escape HTML ด้วย
+// เพราะ JSON handler ของ slog ไม่ escape <, > และ & และ log มักถูกเปิดดูผ่านหน้าเว็บ
+func sanitizeLogText(s string) string {
+	return html.EscapeString(sanitizeText(s))
+}
+
+// sanitizeErrors คืนสำเนาของ errs ที่ข้อความและชื่อฟิลด์ผ่าน sanitizeText แล้ว
+func sanitizeErrors(errs []AppError) []AppError {
+	sanitized := make([]AppError, len(errs))
+	for i, e := range errs {
+		sanitized[i] = AppError{Code: e.Code, Message: sanitizeText(e.Message), Field: sanitizeText(e.Field)}
+	}
+	return sanitized
+}
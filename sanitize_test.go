@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hostilePayload มีทั้ง HTML, CR/LF ที่ใช้ปลอมบรรทัดของ log และ ANSI escape ที่เปลี่ยนสีของ terminal
+const hostilePayload = "<script>alert(1)</script>\r\nfake log line\x1b[31mred"
+
+// expectNoControl ตรวจว่า s ไม่มีอักขระควบคุมเหลืออยู่
+func expectNoControl(t *testing.T, what, s string) {
+	t.Helper()
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			t.Errorf("%s contains control character %U: %q", what, r, s)
+			return
+		}
+	}
+}
+
+// expectNoRawPayload ตรวจว่า bytes ที่ส่งออกไม่มี tag HTML หรืออักขระควบคุมของ payload แบบดิบ
+func expectNoRawPayload(t *testing.T, what string, raw []byte) {
+	t.Helper()
+	for _, bad := range []string{"<script>", "\r", "\x1b"} {
+		if strings.Contains(string(raw), bad) {
+			t.Errorf("%s contains raw %q: %s", what, bad, raw)
+		}
+	}
+	if strings.Count(strings.TrimSpace(string(raw)), "\n") != 0 {
+		t.Errorf("%s spans several lines: %s", what, raw)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{hostilePayload, "<script>alert(1)</script>fake log line[31mred"},
+		{"ผัดไทย\tกุ้งสด", "ผัดไทยกุ้งสด"},
+		{"​zero width space", "​zero width space"},
+		{strings.Repeat("ก", maxReflectedLength), strings.Repeat("ก", maxReflectedLength)},
+		{strings.Repeat("ก", maxReflectedLength+1), strings.Repeat("ก", maxReflectedLength) + "…"},
+		// อักขระควบคุมถูกลบก่อนตัด จึงไม่นับรวมในความยาว
+		{strings.Repeat("\n", 10) + strings.Repeat("ก", maxReflectedLength), strings.Repeat("ก", maxReflectedLength)},
+	}
+	for _, tc := range cases {
+		got := sanitizeText(tc.in)
+		if got != tc.want {
+			t.Errorf("sanitizeText(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("sanitizeText(%q) is not valid UTF-8", tc.in)
+		}
+	}
+
+	errs := sanitizeErrors([]AppError{{Code: codeValidationFailed, Field: hostilePayload, Message: hostilePayload}})
+	expectNoControl(t, "sanitized field", errs[0].Field)
+	expectNoControl(t, "sanitized message", errs[0].Message)
+	if errs[0].Code != codeValidationFailed {
+		t.Errorf("code = %q, want it unchanged", errs[0].Code)
+	}
+}
+
+func TestErrorResponsesNeverReflectRawInput(t *testing.T) {
+	server := newTestServer(t, nil)
+	key, err := json.Marshal(hostilePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	longThai := strings.Repeat("ผัดไทย่", 100)
+
+	cases := []struct {
+		name, method, path, body string
+	}{
+		{"unknown field", http.MethodPost, "/recipes", `{"name":"Pad Thai","description":"d","equipment":[],` + string(key) + `:1}`},
+		{"metadata filter", http.MethodGet, "/recipes?" + url.Values{"metadata." + hostilePayload: {"1"}}.Encode(), ""},
+		{"long thai metadata filter", http.MethodGet, "/recipes?" + url.Values{"metadata." + longThai: {"1"}}.Encode(), ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := server.do(tc.method, tc.path, tc.body, writeHeaders...)
+			if w.Code < http.StatusBadRequest {
+				t.Fatalf("status = %d, want an error", w.Code)
+			}
+			expectNoRawPayload(t, "body", w.Body.Bytes())
+
+			envelope := decodeEnvelope(t, w, nil)
+			if len(envelope.Errors) == 0 {
+				t.Fatalf("no errors in %s", w.Body.String())
+			}
+			for _, e := range envelope.Errors {
+				expectNoControl(t, "message", e.Message)
+				expectNoControl(t, "field", e.Field)
+				for _, s := range []string{e.Message, e.Field} {
+					if n := utf8.RuneCountInString(s); n > maxReflectedLength+1 {
+						t.Errorf("reflected %d characters, want at most %d plus an ellipsis", n, maxReflectedLength)
+					}
+					if !utf8.ValidString(s) {
+						t.Errorf("reflected text %q is not valid UTF-8", s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogNeverContainsRawInput(t *testing.T) {
+	logs := captureAuditLog(t)
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	adminToken := testToken(t, roleAdmin)
+
+	reasons := []string{hostilePayload, strings.Repeat("ก่", 250)}
+	for _, reason := range reasons {
+		body, err := json.Marshal(freezeRequest{Reason: reason})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectStatus(t, server.do(http.MethodPost, "/admin/recipes/1/freeze", string(body), "Authorization", "Bearer "+adminToken), http.StatusOK)
+	}
+
+	var actions []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry struct {
+			Msg    string `json:"msg"`
+			Action string `json:"action"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON (a CR/LF was not removed?): %q", line)
+		}
+		if entry.Msg != "audit" {
+			continue
+		}
+		expectNoRawPayload(t, "audit log line", []byte(line))
+		expectNoControl(t, "audit action", entry.Action)
+		if n := utf8.RuneCountInString(entry.Action); n > maxReflectedLength+1 {
+			t.Errorf("audit action has %d characters, want at most %d plus an ellipsis", n, maxReflectedLength)
+		}
+		if !utf8.ValidString(entry.Action) {
+			t.Errorf("audit action %q is not valid UTF-8", entry.Action)
+		}
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != len(reasons) {
+		t.Fatalf("audit actions = %q, want %d", actions, len(reasons))
+	}
+	if !strings.Contains(actions[0], "&lt;script&gt;alert(1)&lt;/script&gt;fake log line") {
+		t.Errorf("audit action %q, want the HTML escaped and the readable text kept", actions[0])
+	}
+}
+
+func TestRequestLogNeverContainsRawPath(t *testing.T) {
+	router, buf := newLoggingRouter(t)
+	performRequest(router, http.MethodGet, "/recipes/"+url.PathEscape(hostilePayload), "")
+
+	line := strings.TrimSpace(buf.String())
+	expectNoRawPayload(t, "request log line", []byte(line))
+	entry := decodeLogLine(t, buf)
+	path, _ := entry["path"].(string)
+	expectNoControl(t, "logged path", path)
+	if !strings.Contains(path, "&lt;script&gt;") {
+		t.Errorf("logged path = %q, want the HTML escaped", path)
+	}
+}
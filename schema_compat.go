@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+)
+
+// ช่วง version ของ schema ที่ binary นี้ทำงานด้วยได้
+//
+// กติกาของ migration เพื่อให้ deploy แบบไม่มี downtime ได้ (migration รันก่อน pod เก่าจะถูกแทนที่):
+//   - store ต้อง SELECT แบบระบุคอลัมน์เสมอ (ดู recipeColumns) ห้ามใช้ SELECT *
+//   - คอลัมน์ใหม่ต้องเพิ่มแบบ NULL หรือมี DEFAULT ก่อน แล้วจึงเปลี่ยนเป็น NOT NULL ใน migration ถัดไป
+//     หลังจากที่ binary ทุกตัวเขียนค่าของคอลัมน์นั้นแล้ว
+//
+// minSchemaVersion คือ migration ล่าสุดที่โค้ดนี้อ้างถึงคอลัมน์ของมัน ต้องขยับเมื่อโค้ดเริ่มใช้คอลัมน์ใหม่
+// ยกเว้นตารางที่ store ตรวจ version ก่อนใช้เสมอ เช่น ingredient (ดู ingredientsMigration)
+// schema ที่เก่ากว่านี้ไม่มีคอลัมน์ที่ recipeColumns เลือก แม้แต่การอ่านก็ล้มเหลว เซิร์ฟเวอร์จึงไม่ยอมเริ่ม
+// maxSchemaVersion ยอมให้ schema ใหม่กว่า binary ได้หนึ่ง migration ซึ่งต้องเป็นแบบเพิ่มคอลัมน์ตามกติกาข้างต้น
+// schema ที่ใหม่กว่านั้นยังอ่านได้ แต่เซิร์ฟเวอร์จะปิดการเขียน
+const (
+	minSchemaVersion = 13
+	maxSchemaVersion = minSchemaVersion + 1
+)
+
+var (
+	// ErrSchemaIncompatible คือ error เมื่อ schema ใหม่กว่าที่ binary รองรับ เซิร์ฟเวอร์ยังอ่านได้แต่ต้องปิดการเขียน
+	ErrSchemaIncompatible = errors.New("schema version is newer than this binary supports")
+
+	// ErrSchemaTooOld คือ error เมื่อ schema เก่ากว่า minSchemaVersion ซึ่งเซิร์ฟเวอร์ให้บริการไม่ได้เลย
+	ErrSchemaTooOld = errors.New("schema version is older than this binary requires")
+)
+
+// migrationNumber คืนเลขลำดับจาก version ของ migration เช่น 0012_add_recipe_metadata.sql คืน 12
+func migrationNumber(version string) (int, error) {
+	prefix, _, _ := strings.Cut(version, "_")
+	return strconv.Atoi(prefix)
+}
+
+// schemaVersion คืนเลขลำดับของ migration ล่าสุดที่รันแล้วในฐานข้อมูล ถ้ายังไม่เคยรัน migration จะคืน 0
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	latest := 0
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+		number, err := migrationNumber(version)
+		if err != nil {
+			return 0, fmt.Errorf("schema_migrations: unexpected version %q", version)
+		}
+		if number > latest {
+			latest = number
+		}
+	}
+	return latest, rows.Err()
+}
+
+// CheckSchemaCompatibility ตรวจสอบว่า schema ในฐานข้อมูลอยู่ในช่วงที่ binary นี้รองรับ และคืน version ที่อ่านได้
+// ถ้าไม่อยู่ในช่วงจะคืน error ที่ห่อ ErrSchemaTooOld หรือ ErrSchemaIncompatible ส่วน error อื่นคือการอ่าน version ไม่สำเร็จ
+func CheckSchemaCompatibility(ctx context.Context, db *sql.DB) (int, error) {
+	version, err := schemaVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	return version, checkSchemaRange(version)
+}
+
+// checkSchemaRange ตรวจสอบ version กับช่วงที่ binary รองรับ
+func checkSchemaRange(version int) error {
+	switch {
+	case version < minSchemaVersion:
+		return fmt.Errorf("%w: database is at %d, this binary supports %d to %d; run the migrations first", ErrSchemaTooOld, version, minSchemaVersion, maxSchemaVersion)
+	case version > maxSchemaVersion:
+		return fmt.Errorf("%w: database is at %d, this binary supports %d to %d", ErrSchemaIncompatible, version, minSchemaVersion, maxSchemaVersion)
+	}
+	return nil
+}
+
+// readOnlySafeRoutes คือ route ที่ไม่ใช่ GET แต่ไม่เขียนข้อมูล จึงยังทำงานได้ระหว่างที่ปิดการเขียน
+// route ที่เพิ่มใหม่จะถูกปิดไว้ก่อนจนกว่าจะเพิ่มเข้ามาในรายการนี้
+var readOnlySafeRoutes = map[string]bool{
+	http.MethodPost + " /recipes/fetch": true,
+	http.MethodPost + " /auth/login":    true,
+}
+
+// ReadOnlyMiddleware ปฏิเสธ request ที่เขียนข้อมูลด้วย 503 ระหว่างที่ schema ไม่ตรงกับ binary
+// request ที่อ่านอย่างเดียว (GET, HEAD, OPTIONS) และ route ใน readOnlySafeRoutes ยังทำงานต่อได้
+func ReadOnlyMiddleware(reason string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if readOnlySafeRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+		respondErr(c, http.StatusServiceUnavailable, codeReadOnly, "writes are disabled: "+reason)
+		c.Abort()
+	}
+}
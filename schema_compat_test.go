@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCheckSchemaRange(t *testing.T) {
+	cases := []struct {
+		version int
+		want    error
+	}{
+		// binary ใหม่บน schema เก่า: ขาดคอลัมน์ที่ recipeColumns เลือก จึงเริ่มไม่ได้
+		{minSchemaVersion - 1, ErrSchemaTooOld},
+		{minSchemaVersion, nil},
+		{maxSchemaVersion, nil},
+		// binary เก่าบน schema ใหม่: อ่านได้แต่ต้องปิดการเขียน
+		{maxSchemaVersion + 1, ErrSchemaIncompatible},
+	}
+	for _, tc := range cases {
+		err := checkSchemaRange(tc.version)
+		if tc.want == nil && err != nil {
+			t.Errorf("checkSchemaRange(%d) = %v, want nil", tc.version, err)
+		}
+		if tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("checkSchemaRange(%d) = %v, want %v", tc.version, err, tc.want)
+		}
+	}
+
+	// schema เก่าไม่ใช่แค่โหมดอ่านอย่างเดียว จึงต้องไม่ถูกจัดเป็น ErrSchemaIncompatible
+	if err := checkSchemaRange(minSchemaVersion - 1); errors.Is(err, ErrSchemaIncompatible) {
+		t.Errorf("old schema error %v would put the server in read-only mode", err)
+	}
+}
+
+func TestMigrationNumber(t *testing.T) {
+	if n, err := migrationNumber("0012_add_recipe_metadata.sql"); err != nil || n != 12 {
+		t.Errorf("migrationNumber = %d, %v; want 12", n, err)
+	}
+	if _, err := migrationNumber("latest.sql"); err == nil {
+		t.Error("migrationNumber without a numeric prefix: expected an error")
+	}
+}
+
+// newReadOnlyTestServer สร้างเซิร์ฟเวอร์เหมือน newTestServer แต่ปิดการเขียนเหมือนตอนที่ schema ใหม่กว่า binary
+// โดยมี Recipe หนึ่งรายการไว้ให้อ่าน
+func newReadOnlyTestServer(t *testing.T) *testServer {
+	t.Helper()
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.StoreBackend = "memory"
+	cfg.JWTSecret = testJWTSecret
+	cfg.APIKeys = []string{testAPIKey}
+	cfg.APIKeysFile = ""
+	cfg.AuthDisabled = false
+
+	server := &testServer{
+		store:    NewMemoryStore(),
+		users:    NewMemoryUserStore(),
+		registry: prometheus.NewRegistry(),
+	}
+	mustAdd(t, server.store, Recipe{Name: "Pad Thai", Description: "d", Equipment: []string{"wok"}})
+	server.router, err = newRouter(routerDeps{
+		Config:         cfg,
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Registry:       server.registry,
+		Store:          server.store,
+		Users:          server.users,
+		Lifecycle:      NewLifecycle(),
+		ReadOnlyReason: checkSchemaRange(maxSchemaVersion + 1).Error(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestReadOnlyModeRejectsWrites(t *testing.T) {
+	server := newReadOnlyTestServer(t)
+	recipe := `{"name":"Tom Yum","description":"d","equipment":[]}`
+
+	writes := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/recipes", recipe},
+		{http.MethodPost, "/recipes/batch", `[` + recipe + `]`},
+		{http.MethodPut, "/recipes/1", recipe},
+		{http.MethodPatch, "/recipes/1", `{"description":"x"}`},
+		{http.MethodDelete, "/recipes/1", ""},
+		{http.MethodPost, "/recipes/1/ingredients", `{"name":"egg","quantity":1,"unit":"g"}`},
+		{http.MethodDelete, "/recipes/1/ingredients/1", ""},
+		{http.MethodPost, "/auth/register", `{"username":"alice","email":"alice@example.com","password":"secret123"}`},
+	}
+	for _, tc := range writes {
+		w := server.do(tc.method, tc.path, tc.body, writeHeaders...)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, w.Code, http.StatusServiceUnavailable)
+			continue
+		}
+		if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeReadOnly {
+			t.Errorf("%s %s errors = %+v, want %s", tc.method, tc.path, envelope.Errors, codeReadOnly)
+		}
+	}
+
+	// การเขียนที่ถูกปฏิเสธต้องไม่เปลี่ยนข้อมูล
+	if got := mustGet(t, server.store, 1); got.Name != "Pad Thai" {
+		t.Errorf("recipe 1 = %+v after rejected writes", got)
+	}
+}
+
+func TestReadOnlyModeServesReads(t *testing.T) {
+	server := newReadOnlyTestServer(t)
+
+	reads := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/recipes", "", http.StatusOK},
+		{http.MethodGet, "/recipes/1", "", http.StatusOK},
+		// ไม่มี route ของ HEAD แต่ต้องได้ 404 จาก router ไม่ใช่ 503 จาก middleware
+		{http.MethodHead, "/recipes/1", "", http.StatusNotFound},
+		{http.MethodPost, "/recipes/fetch", `{"names":["Pad Thai"]}`, http.StatusOK},
+		// login ไม่เขียนข้อมูล จึงยังผ่าน middleware ไปถึง handler ซึ่งปฏิเสธผู้ใช้ที่ไม่มีอยู่
+		{http.MethodPost, "/auth/login", `{"username":"nobody","password":"secret123"}`, http.StatusUnauthorized},
+	}
+	for _, tc := range reads {
+		w := server.do(tc.method, tc.path, tc.body)
+		if w.Code != tc.want {
+			t.Errorf("%s %s status = %d, want %d; body %s", tc.method, tc.path, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import "strings"
+
+// likeEscape คืออักขระ escape ที่ใช้กับ LIKE ใช้ ! แทน \ เพื่อไม่ขึ้นกับ sql_mode NO_BACKSLASH_ESCAPES
+const likeEscape = "!"
+
+// likePattern สร้าง pattern ของ LIKE ที่ค้นหา query เป็นสตริงย่อย
+// อักขระ % และ _ ที่ผู้ใช้พิมพ์จะถูก escape จึงค้นหาเป็นตัวอักษรธรรมดา
+func likePattern(query string) string {
+	escaped := strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").Replace(strings.ToLower(query))
+	return "%" + escaped + "%"
+}
+
+// matchesQuery ตรวจสอบว่าชื่อหรือคำอธิบายของ Recipe มี query อยู่ โดยไม่สนตัวพิมพ์เล็กใหญ่
+func matchesQuery(recipe Recipe, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(recipe.Name), query) || strings.Contains(strings.ToLower(recipe.Description), query)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLikePattern(t *testing.T) {
+	cases := []struct {
+		query, want string
+	}{
+		{"pad thai", "%pad thai%"},
+		{"Pad Thai", "%pad thai%"},
+		{"100%", "%100!%%"},
+		{"a_b", "%a!_b%"},
+		{"wow!", "%wow!!%"},
+		{"ผัดไทย", "%ผัดไทย%"},
+	}
+	for _, tc := range cases {
+		if got := likePattern(tc.query); got != tc.want {
+			t.Errorf("likePattern(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestSearchRecipes(t *testing.T) {
+	server := newTestServer(t, nil)
+	for _, body := range []string{
+		`{"name":"Pad Thai","description":"Stir-fried rice noodles","equipment":[]}`,
+		`{"name":"ผัดไทย","description":"เส้นจันทน์ผัดกับกุ้ง","equipment":[]}`,
+		`{"name":"Lime Soda","description":"100% lime juice","equipment":[]}`,
+		`{"name":"Tom_Yum","description":"hot and sour soup","equipment":[]}`,
+		`{"name":"Tom Kha","description":"coconut soup","equipment":[]}`,
+	} {
+		server.createRecipe(t, body)
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"pad thai", []string{"Pad Thai"}},
+		{"NOODLES", []string{"Pad Thai"}},
+		{"ผัด", []string{"ผัดไทย"}},
+		{"กุ้ง", []string{"ผัดไทย"}},
+		// % และ _ ถูกค้นหาเป็นตัวอักษรธรรมดา ไม่ใช่ wildcard
+		{"100%", []string{"Lime Soda"}},
+		{"%", []string{"Lime Soda"}},
+		{"tom_", []string{"Tom_Yum"}},
+		{"_", []string{"Tom_Yum"}},
+		{"soup", []string{"Tom_Yum", "Tom Kha"}},
+		// query ว่างเหมือนการดึงรายการปกติ
+		{"", []string{"Pad Thai", "ผัดไทย", "Lime Soda", "Tom_Yum", "Tom Kha"}},
+	}
+	for _, tc := range cases {
+		page := server.listRecipes(t, "/recipes?q="+url.QueryEscape(tc.query))
+		if got := recipeNames(page.Recipes); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("q=%q: got %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestSearchWithoutResultsReturnsEmptyList(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodGet, "/recipes?q=pizza", "")
+	expectStatus(t, w, http.StatusOK)
+	envelope := decodeEnvelope(t, w, nil)
+	if !strings.Contains(string(envelope.Data), `"recipes":[]`) {
+		t.Errorf("data = %s, want an empty recipes array", envelope.Data)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+)
+
+// serve เริ่มเซิร์ฟเวอร์ HTTP และรอจนได้รับ SIGINT หรือ SIGTERM
+// เมื่อได้รับสัญญาณจะหยุดรับการเชื่อมต่อใหม่ และรอ request ที่กำลังทำงานให้เสร็จภายใน cfg.ShutdownTimeout
+func serve(handler http.Handler, cfg Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: cfg.ListenAddr, Handler: handler}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		// เซิร์ฟเวอร์หยุดเองก่อนได้รับสัญญาณ เช่น address ถูกใช้อยู่
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Printf("shutting down, waiting up to %s for in-flight requests", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeDrainsInFlightRequestsOnSIGTERM(t *testing.T) {
+	// handler ที่ช้า: รอจน test ส่ง SIGTERM แล้วจึงตอบ
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		io.WriteString(w, "done")
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+	cfg := Config{ListenAddr: freeAddr(t), ShutdownTimeout: 5 * time.Second}
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(mux, cfg)
+	}()
+
+	baseURL := "http://" + cfg.ListenAddr
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(baseURL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-entered
+
+	// serve จับ SIGTERM ผ่าน signal.NotifyContext จึงส่งให้ process ของ test เองได้
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	// ระหว่างรอ request ที่ค้างอยู่ serve ต้องยังไม่จบ
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned %v before the in-flight request finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	res := <-slow
+	if res.err != nil || res.status != http.StatusOK || res.body != "done" {
+		t.Errorf("slow request = %d %q, %v; want it to complete", res.status, res.body, res.err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the in-flight request finished")
+	}
+
+	// หลังหยุดแล้วเซิร์ฟเวอร์ไม่รับการเชื่อมต่อใหม่
+	if resp, err := http.Get(baseURL + "/healthz"); err == nil {
+		resp.Body.Close()
+		t.Error("server still accepts connections after shutdown")
+	}
+}
@@ -0,0 +1,587 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// otherTenantID คือ tenant ที่ใช้ตรวจว่าข้อมูลของแต่ละ tenant แยกจากกัน
+const otherTenantID = "other"
+
+// testStoreConformance ตรวจว่า recipeStore ทำงานตามความหมายเดียวกันทุก implement
+// newStore ต้องคืน store ใหม่ที่ว่างเปล่าทุกครั้ง เพราะแต่ละ test รันขนานกัน
+func testStoreConformance(t *testing.T, newStore func(t *testing.T) recipeStore) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, store recipeStore)
+	}{
+		{"AddThenGet", conformAddThenGet},
+		{"GetMissing", conformGetMissing},
+		{"DuplicateName", conformDuplicateName},
+		{"DuplicateExternalID", conformDuplicateExternalID},
+		{"EquipmentCaseInsensitive", conformEquipmentCaseInsensitive},
+		{"GetManyCaseInsensitive", conformGetManyCaseInsensitive},
+		{"UpdateSameValues", conformUpdateSameValues},
+		{"Timestamps", conformTimestamps},
+		{"UpdateMissing", conformUpdateMissing},
+		{"RenameKeepsEquipment", conformRenameKeepsEquipment},
+		{"PatchReturnsStoredRecipe", conformPatchReturnsStoredRecipe},
+		{"Remove", conformRemove},
+		{"Frozen", conformFrozen},
+		{"AddBatchAllOrNothing", conformAddBatchAllOrNothing},
+		{"PatchBatch", conformPatchBatch},
+		{"ListPaging", conformListPaging},
+		{"ListFilters", conformListFilters},
+		{"FindByExternalID", conformFindByExternalID},
+		{"ListEquipment", conformListEquipment},
+		{"Ingredients", conformIngredients},
+		{"TenantIsolation", conformTenantIsolation},
+		{"Ping", conformPing},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tc.run(t, newStore(t))
+		})
+	}
+}
+
+// mustAdd เพิ่ม recipe และคืน id ให้ test หยุดทันทีถ้าเพิ่มไม่สำเร็จ
+func mustAdd(t *testing.T, store recipeStore, recipe Recipe) int64 {
+	t.Helper()
+	id, err := store.Add(context.Background(), defaultTenantID, recipe)
+	if err != nil {
+		t.Fatalf("Add(%q): %v", recipe.Name, err)
+	}
+	return id
+}
+
+// mustGet ดึง recipe ให้ test หยุดทันทีถ้าดึงไม่สำเร็จ
+func mustGet(t *testing.T, store recipeStore, id int64) Recipe {
+	t.Helper()
+	recipe, err := store.Get(context.Background(), defaultTenantID, id)
+	if err != nil {
+		t.Fatalf("Get(%d): %v", id, err)
+	}
+	return recipe
+}
+
+// expectConflict ตรวจว่า err เป็น ConflictError ของ field
+func expectConflict(t *testing.T, err error, field string) {
+	t.Helper()
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Field != field {
+		t.Fatalf("err = %v, want conflict on %s", err, field)
+	}
+	if !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("err = %v, want errors.Is ErrDuplicate", err)
+	}
+}
+
+// expectNotFound ตรวจว่า err เป็น ErrNotFound
+func expectNotFound(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func conformAddThenGet(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{
+		Name:           "Pad Thai",
+		Description:    "noodles",
+		SourceURL:      "https://example.com/pad-thai",
+		SourceName:     "Example",
+		ExternalID:     "ext-1",
+		ExternalSource: "partner",
+		Equipment:      []string{"pan", "wok"},
+		Metadata:       map[string]interface{}{"cuisine": "thai"},
+	})
+
+	got := mustGet(t, store, id)
+	if got.ID != id || got.Name != "Pad Thai" || got.Description != "noodles" ||
+		got.SourceURL != "https://example.com/pad-thai" || got.SourceName != "Example" ||
+		got.ExternalID != "ext-1" || got.ExternalSource != "partner" {
+		t.Errorf("Get = %+v", got)
+	}
+	if !reflect.DeepEqual(got.Equipment, []string{"pan", "wok"}) {
+		t.Errorf("Equipment = %v", got.Equipment)
+	}
+	if got.Metadata["cuisine"] != "thai" {
+		t.Errorf("Metadata = %v", got.Metadata)
+	}
+	if got.CreatedAt.IsZero() || got.UpdatedAt.Before(got.CreatedAt) {
+		t.Errorf("CreatedAt = %v, UpdatedAt = %v", got.CreatedAt, got.UpdatedAt)
+	}
+	if got.Ingredients == nil || len(got.Ingredients) != 0 {
+		t.Errorf("Ingredients = %#v, want empty list", got.Ingredients)
+	}
+}
+
+func conformGetMissing(t *testing.T, store recipeStore) {
+	_, err := store.Get(context.Background(), defaultTenantID, 999)
+	expectNotFound(t, err)
+}
+
+func conformDuplicateName(t *testing.T, store recipeStore) {
+	mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{}})
+
+	// unique key ของชื่อใช้ collation ที่ไม่สนใจตัวพิมพ์
+	for _, name := range []string{"Pad Thai", "pad thai"} {
+		_, err := store.Add(context.Background(), defaultTenantID, Recipe{Name: name, Equipment: []string{}})
+		expectConflict(t, err, "name")
+	}
+}
+
+func conformDuplicateExternalID(t *testing.T, store recipeStore) {
+	mustAdd(t, store, Recipe{Name: "First", ExternalID: "ext-1", ExternalSource: "partner", Equipment: []string{}})
+	// recipe ที่ไม่มี ID ภายนอกหลายรายการต้องอยู่ร่วมกันได้
+	mustAdd(t, store, Recipe{Name: "Second", Equipment: []string{}})
+	mustAdd(t, store, Recipe{Name: "Third", Equipment: []string{}})
+
+	_, err := store.Add(context.Background(), defaultTenantID, Recipe{Name: "Fourth", ExternalID: "ext-1", ExternalSource: "partner", Equipment: []string{}})
+	expectConflict(t, err, "external_id")
+}
+
+func conformEquipmentCaseInsensitive(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{Name: "Stir Fry", Equipment: normalizeEquipment([]string{"Wok", "wok", " WOK "})})
+	if got := mustGet(t, store, id).Equipment; !reflect.DeepEqual(got, []string{"Wok"}) {
+		t.Errorf("Equipment = %v, want [Wok]", got)
+	}
+}
+
+func conformGetManyCaseInsensitive(t *testing.T, store recipeStore) {
+	mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+	mustAdd(t, store, Recipe{Name: "Tom Yum", Equipment: []string{}})
+
+	recipes, err := store.GetMany(context.Background(), defaultTenantID, []string{"pad thai", "Tom Yum", "Som Tam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipes) != 2 {
+		t.Fatalf("GetMany returned %d recipes, want 2: %v", len(recipes), recipes)
+	}
+	if got := recipes["pad thai"]; got.Name != "Pad Thai" || !reflect.DeepEqual(got.Equipment, []string{"wok"}) {
+		t.Errorf(`recipes["pad thai"] = %+v`, got)
+	}
+	if got := recipes["Tom Yum"]; got.Name != "Tom Yum" || got.Equipment == nil {
+		t.Errorf(`recipes["Tom Yum"] = %+v`, got)
+	}
+}
+
+func conformUpdateSameValues(t *testing.T, store recipeStore) {
+	recipe := Recipe{Name: "Pad Thai", Description: "noodles", Equipment: []string{"wok"}}
+	id := mustAdd(t, store, recipe)
+
+	// UPDATE ด้วยค่าเดิมต้องไม่ถูกตีความว่าไม่พบ Recipe
+	if err := store.Update(context.Background(), defaultTenantID, id, recipe); err != nil {
+		t.Fatalf("Update with unchanged values: %v", err)
+	}
+}
+
+func conformTimestamps(t *testing.T, store recipeStore) {
+	// store กำหนดเวลาเอง ค่าที่ผู้เรียกส่งมาถูกละเลย
+	forged := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Description: "d", Equipment: []string{}, CreatedAt: forged, UpdatedAt: forged})
+	created := mustGet(t, store, id)
+	if created.CreatedAt.Equal(forged) || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("after Add: created_at %s, updated_at %s", created.CreatedAt, created.UpdatedAt)
+	}
+
+	// TIMESTAMP ของ MySQL ละเอียดระดับวินาที จึงต้องรอให้ข้ามวินาทีก่อนอัปเดต
+	time.Sleep(1100 * time.Millisecond)
+	if err := store.Update(context.Background(), defaultTenantID, id, Recipe{Name: "Pad Thai", Description: "changed", Equipment: []string{}, CreatedAt: forged}); err != nil {
+		t.Fatal(err)
+	}
+	updated := mustGet(t, store, id)
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Update changed created_at from %s to %s", created.CreatedAt, updated.CreatedAt)
+	}
+	if !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Errorf("Update left updated_at at %s, want after %s", updated.UpdatedAt, created.UpdatedAt)
+	}
+}
+
+func conformUpdateMissing(t *testing.T, store recipeStore) {
+	err := store.Update(context.Background(), defaultTenantID, 999, Recipe{Name: "Ghost", Equipment: []string{}})
+	expectNotFound(t, err)
+}
+
+func conformRenameKeepsEquipment(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+	other := mustAdd(t, store, Recipe{Name: "Tom Yum", Equipment: []string{}})
+
+	err := store.Update(context.Background(), defaultTenantID, id, Recipe{Name: "Phad Thai", Equipment: []string{"pan", "wok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mustGet(t, store, id)
+	if got.Name != "Phad Thai" || !reflect.DeepEqual(got.Equipment, []string{"pan", "wok"}) {
+		t.Errorf("after rename Get = %+v", got)
+	}
+
+	err = store.Update(context.Background(), defaultTenantID, other, Recipe{Name: "PHAD THAI", Equipment: []string{}})
+	expectConflict(t, err, "name")
+}
+
+func conformPatchReturnsStoredRecipe(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Description: "noodles", Equipment: []string{"wok"}})
+	if _, err := store.AddIngredient(context.Background(), defaultTenantID, id, Ingredient{Name: "noodles", Quantity: 200, Unit: "g"}); err != nil {
+		t.Fatal(err)
+	}
+	before := mustGet(t, store, id)
+
+	description := "rice noodles"
+	patched, err := store.Patch(context.Background(), defaultTenantID, id, RecipePatch{Description: &description})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := mustGet(t, store, id)
+	if !reflect.DeepEqual(patched, stored) {
+		t.Errorf("Patch returned %+v, Get returns %+v", patched, stored)
+	}
+	if patched.Description != description || !reflect.DeepEqual(patched.Equipment, []string{"wok"}) {
+		t.Errorf("Patch = %+v", patched)
+	}
+	if patched.UpdatedAt.Before(before.UpdatedAt) {
+		t.Errorf("UpdatedAt went backwards: %v -> %v", before.UpdatedAt, patched.UpdatedAt)
+	}
+	if len(patched.Ingredients) != 1 || patched.Ingredients[0].Name != "noodles" {
+		t.Errorf("Ingredients = %+v", patched.Ingredients)
+	}
+
+	_, err = store.Patch(context.Background(), defaultTenantID, 999, RecipePatch{Description: &description})
+	expectNotFound(t, err)
+}
+
+func conformRemove(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+	if _, err := store.AddIngredient(context.Background(), defaultTenantID, id, Ingredient{Name: "noodles", Quantity: 200, Unit: "g"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Remove(context.Background(), defaultTenantID, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.Get(context.Background(), defaultTenantID, id)
+	expectNotFound(t, err)
+	expectNotFound(t, store.Remove(context.Background(), defaultTenantID, id))
+
+	// ชื่อเดิมใช้ได้อีกครั้งหลังจากลบ
+	mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+}
+
+func conformFrozen(t *testing.T, store recipeStore) {
+	ctx := context.Background()
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{}})
+	if err := store.SetFrozen(ctx, defaultTenantID, id, true, "audit"); err != nil {
+		t.Fatal(err)
+	}
+	// ล็อกซ้ำด้วยค่าเดิมต้องสำเร็จ ไม่ใช่ถูกตีความว่าไม่พบ Recipe
+	if err := store.SetFrozen(ctx, defaultTenantID, id, true, "audit"); err != nil {
+		t.Fatalf("SetFrozen with the same values: %v", err)
+	}
+
+	description := "changed"
+	checks := map[string]error{
+		"Update": store.Update(ctx, defaultTenantID, id, Recipe{Name: "Pad Thai", Equipment: []string{}}),
+		"Remove": store.Remove(ctx, defaultTenantID, id),
+	}
+	_, checks["Patch"] = store.Patch(ctx, defaultTenantID, id, RecipePatch{Description: &description})
+	_, checks["AddIngredient"] = store.AddIngredient(ctx, defaultTenantID, id, Ingredient{Name: "salt", Quantity: 1, Unit: "g"})
+	for op, err := range checks {
+		if !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s on frozen recipe: err = %v, want ErrFrozen", op, err)
+		}
+	}
+
+	if err := store.SetFrozen(ctx, defaultTenantID, id, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Patch(ctx, defaultTenantID, id, RecipePatch{Description: &description}); err != nil {
+		t.Errorf("Patch after unfreeze: %v", err)
+	}
+	expectNotFound(t, store.SetFrozen(ctx, defaultTenantID, 999, true, "audit"))
+	expectNotFound(t, store.SetFrozen(ctx, otherTenantID, id, true, "audit"))
+}
+
+func conformAddBatchAllOrNothing(t *testing.T, store recipeStore) {
+	ctx := context.Background()
+	mustAdd(t, store, Recipe{Name: "Existing", ExternalID: "ext-1", ExternalSource: "partner", Equipment: []string{}})
+
+	_, err := store.AddBatch(ctx, defaultTenantID, []Recipe{
+		{Name: "New", Equipment: []string{"wok"}},
+		{Name: "Other", ExternalID: "ext-1", ExternalSource: "partner", Equipment: []string{}},
+	})
+	var batchErr *BatchAddError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+		t.Fatalf("err = %v, want BatchAddError at index 1", err)
+	}
+	expectConflict(t, err, "external_id")
+
+	recipes, err := store.GetMany(ctx, defaultTenantID, []string{"New"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipes) != 0 {
+		t.Errorf("failed batch left %v behind", recipes)
+	}
+
+	ids, err := store.AddBatch(ctx, defaultTenantID, []Recipe{
+		{Name: "New", Equipment: []string{"wok"}},
+		{Name: "Other", Equipment: []string{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || mustGet(t, store, ids[0]).Name != "New" || mustGet(t, store, ids[1]).Name != "Other" {
+		t.Errorf("AddBatch ids = %v", ids)
+	}
+}
+
+func conformPatchBatch(t *testing.T, store recipeStore) {
+	ctx := context.Background()
+	first := mustAdd(t, store, Recipe{Name: "First", Description: "a", Equipment: []string{}})
+	mustAdd(t, store, Recipe{Name: "Second", Description: "b", Equipment: []string{}})
+
+	changed := "changed"
+	taken := "second"
+	items := []RecipePatchItem{
+		{Name: "first", Patch: RecipePatch{Description: &changed}},
+		{Name: "Missing", Patch: RecipePatch{Description: &changed}},
+		{Name: "First", Patch: RecipePatch{Name: &taken}},
+	}
+
+	// ใน transaction เดียวรายการที่สำเร็จจะถูก rollback เมื่อมีรายการล้มเหลว
+	results, err := store.PatchBatch(ctx, defaultTenantID, items, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{batchStatusRolledBack, batchStatusNotFound, batchStatusConflict}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("all-or-nothing results[%d] = %+v, want status %s", i, result, want[i])
+		}
+	}
+	if got := mustGet(t, store, first).Description; got != "a" {
+		t.Errorf("Description after rollback = %q, want a", got)
+	}
+
+	results, err = store.PatchBatch(ctx, defaultTenantID, items, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{batchStatusUpdated, batchStatusNotFound, batchStatusConflict}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("continue_on_error results[%d] = %+v, want status %s", i, result, want[i])
+		}
+	}
+	if got := mustGet(t, store, first).Description; got != changed {
+		t.Errorf("Description = %q, want %q", got, changed)
+	}
+}
+
+func conformListPaging(t *testing.T, store recipeStore) {
+	// การเรียงตามชื่อไม่สนใจตัวพิมพ์เหมือน collation ของคอลัมน์ name
+	for _, name := range []string{"apple pie", "Banana Bread", "carrot cake"} {
+		mustAdd(t, store, Recipe{Name: name, Equipment: []string{}})
+	}
+
+	recipes, total, err := store.List(context.Background(), defaultTenantID, RecipeFilter{}, ListOptions{Limit: 2, SortBy: "name", Order: "asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(recipes) != 2 || recipes[0].Name != "apple pie" || recipes[1].Name != "Banana Bread" {
+		t.Errorf("first page = %v (total %d)", recipeNames(recipes), total)
+	}
+
+	recipes, total, err = store.List(context.Background(), defaultTenantID, RecipeFilter{}, ListOptions{Limit: 2, Offset: 2, SortBy: "name", Order: "asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(recipes) != 1 || recipes[0].Name != "carrot cake" {
+		t.Errorf("second page = %v (total %d)", recipeNames(recipes), total)
+	}
+
+	recipes, _, err = store.List(context.Background(), defaultTenantID, RecipeFilter{}, ListOptions{Limit: 1, SortBy: "id", Order: "desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipes) != 1 || recipes[0].Name != "carrot cake" {
+		t.Errorf("newest = %v", recipeNames(recipes))
+	}
+}
+
+func conformListFilters(t *testing.T, store recipeStore) {
+	mustAdd(t, store, Recipe{Name: "Pad Thai", Description: "Noodles", SourceURL: "https://example.com", Equipment: []string{"Wok"}, Metadata: map[string]interface{}{"cuisine": "thai"}})
+	mustAdd(t, store, Recipe{Name: "Pancakes", Description: "breakfast", Equipment: []string{"pan"}, Metadata: map[string]interface{}{"cuisine": "american"}})
+	mustAdd(t, store, Recipe{Name: "100% Juice", Description: "fruit", Equipment: []string{}})
+	mustAdd(t, store, Recipe{Name: "ต้มยำ_กุ้ง", Description: "ซุป", Equipment: []string{}})
+
+	cases := []struct {
+		name   string
+		filter RecipeFilter
+		want   []string
+	}{
+		{"has source", RecipeFilter{HasSource: true}, []string{"Pad Thai"}},
+		{"equipment ignores case", RecipeFilter{Equipment: "wok"}, []string{"Pad Thai"}},
+		{"query ignores case", RecipeFilter{Query: "NOODLE"}, []string{"Pad Thai"}},
+		{"query escapes wildcards", RecipeFilter{Query: "100%"}, []string{"100% Juice"}},
+		{"query escapes underscore", RecipeFilter{Query: "_"}, []string{"ต้มยำ_กุ้ง"}},
+		{"query thai", RecipeFilter{Query: "ซุป"}, []string{"ต้มยำ_กุ้ง"}},
+		{"query without results", RecipeFilter{Query: "pizza"}, []string{}},
+		{"metadata", RecipeFilter{Metadata: map[string]string{"cuisine": "american"}}, []string{"Pancakes"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recipes, total, err := store.List(context.Background(), defaultTenantID, tc.filter, ListOptions{Limit: 10, SortBy: "id", Order: "asc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := recipeNames(recipes); !reflect.DeepEqual(got, tc.want) || total != len(tc.want) {
+				t.Errorf("List = %v (total %d), want %v", got, total, tc.want)
+			}
+		})
+	}
+}
+
+// recipeNames คืนชื่อของ recipes ตามลำดับ
+func recipeNames(recipes []Recipe) []string {
+	names := []string{}
+	for _, recipe := range recipes {
+		names = append(names, recipe.Name)
+	}
+	return names
+}
+
+func conformFindByExternalID(t *testing.T, store recipeStore) {
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", ExternalID: "ext-1", ExternalSource: "partner", Equipment: []string{}})
+
+	got, err := store.FindByExternalID(context.Background(), defaultTenantID, "ext-1", "partner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != id {
+		t.Errorf("FindByExternalID = %+v, want id %d", got, id)
+	}
+	_, err = store.FindByExternalID(context.Background(), defaultTenantID, "ext-1", "other")
+	expectNotFound(t, err)
+}
+
+func conformListEquipment(t *testing.T, store recipeStore) {
+	mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok", "knife"}})
+	mustAdd(t, store, Recipe{Name: "Stir Fry", Equipment: []string{"wok"}})
+
+	equipment, err := store.ListEquipment(context.Background(), defaultTenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EquipmentCount{{Name: "knife", Count: 1}, {Name: "wok", Count: 2}}
+	if !reflect.DeepEqual(equipment, want) {
+		t.Errorf("ListEquipment = %v, want %v", equipment, want)
+	}
+}
+
+func conformIngredients(t *testing.T, store recipeStore) {
+	ctx := context.Background()
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{}})
+
+	noodles, err := store.AddIngredient(ctx, defaultTenantID, id, Ingredient{Name: "noodles", Quantity: 200, Unit: "g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noodles.ID == 0 {
+		t.Error("AddIngredient did not assign an id")
+	}
+	if _, err := store.AddIngredient(ctx, defaultTenantID, id, Ingredient{Name: "egg", Quantity: 2, Unit: "pc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ingredients, err := store.ListIngredients(ctx, defaultTenantID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ingredients) != 2 || ingredients[0] != noodles || ingredients[1].Name != "egg" {
+		t.Errorf("ListIngredients = %+v", ingredients)
+	}
+
+	if err := store.RemoveIngredient(ctx, defaultTenantID, id, noodles.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectNotFound(t, store.RemoveIngredient(ctx, defaultTenantID, id, noodles.ID))
+	if got := mustGet(t, store, id).Ingredients; len(got) != 1 || got[0].Name != "egg" {
+		t.Errorf("Get().Ingredients = %+v", got)
+	}
+
+	// Recipe ที่ไม่มีอยู่ได้ ErrNotFound ไม่ใช่รายการว่าง
+	_, err = store.ListIngredients(ctx, defaultTenantID, 999)
+	expectNotFound(t, err)
+	_, err = store.AddIngredient(ctx, defaultTenantID, 999, Ingredient{Name: "salt", Quantity: 1, Unit: "g"})
+	expectNotFound(t, err)
+
+	// การลบ Recipe ลบวัตถุดิบของมันไปด้วย
+	if err := store.Remove(ctx, defaultTenantID, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.ListIngredients(ctx, defaultTenantID, id)
+	expectNotFound(t, err)
+}
+
+func conformTenantIsolation(t *testing.T, store recipeStore) {
+	ctx := context.Background()
+	id := mustAdd(t, store, Recipe{Name: "Pad Thai", Equipment: []string{"wok"}})
+
+	_, err := store.Get(ctx, otherTenantID, id)
+	expectNotFound(t, err)
+	expectNotFound(t, store.Remove(ctx, otherTenantID, id))
+	_, err = store.ListIngredients(ctx, otherTenantID, id)
+	expectNotFound(t, err)
+
+	// ชื่อเดียวกันใช้ได้ใน tenant อื่น
+	if _, err := store.Add(ctx, otherTenantID, Recipe{Name: "Pad Thai", Equipment: []string{"pan"}}); err != nil {
+		t.Fatal(err)
+	}
+	recipes, total, err := store.List(ctx, otherTenantID, RecipeFilter{}, ListOptions{Limit: 10, SortBy: "id", Order: "asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || !reflect.DeepEqual(recipes[0].Equipment, []string{"pan"}) {
+		t.Errorf("other tenant List = %+v (total %d)", recipes, total)
+	}
+}
+
+func conformPing(t *testing.T, store recipeStore) {
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testUserStoreConformance ตรวจว่า userStore ทำงานตามความหมายเดียวกันทุก implement
+// ชื่อผู้ใช้และอีเมลเทียบโดยไม่สนตัวพิมพ์เหมือน collation ของตาราง users
+func testUserStoreConformance(t *testing.T, users userStore) {
+	ctx := context.Background()
+	if err := users.AddUser(ctx, User{Username: "Alice", Email: "alice@example.com", PasswordHash: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, username := range []string{"Alice", "alice", "ALICE"} {
+		user, err := users.GetUserByUsername(ctx, username)
+		if err != nil {
+			t.Fatalf("GetUserByUsername(%q): %v", username, err)
+		}
+		if user.Username != "Alice" || user.Email != "alice@example.com" || user.TenantID != defaultTenantID || user.Role != "user" {
+			t.Errorf("GetUserByUsername(%q) = %+v", username, user)
+		}
+	}
+
+	expectConflict(t, users.AddUser(ctx, User{Username: "alice", Email: "other@example.com", PasswordHash: "hash"}), "username")
+	expectConflict(t, users.AddUser(ctx, User{Username: "bob", Email: "ALICE@example.com", PasswordHash: "hash"}), "email")
+
+	_, err := users.GetUserByUsername(ctx, "carol")
+	expectNotFound(t, err)
+}
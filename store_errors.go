@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+)
+
+// NotFoundError คือ error เมื่อไม่พบข้อมูล โดยบอกชนิดและ key ของข้อมูลที่ค้นหา
+// errors.Is(err, ErrNotFound) ยังเป็นจริงเพื่อให้โค้ดเดิมทำงานได้
+type NotFoundError struct {
+	Resource string
+	Key      string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Resource, e.Key)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// recipeNotFound สร้าง NotFoundError ของ Recipe จาก id
+func recipeNotFound(id int64) error {
+	return &NotFoundError{Resource: "recipe", Key: strconv.FormatInt(id, 10)}
+}
+
+// ConflictError คือ error เมื่อค่าของฟิลด์ซ้ำกับข้อมูลที่มีอยู่แล้ว
+// errors.Is(err, ErrDuplicate) ยังเป็นจริงเพื่อให้โค้ดเดิมทำงานได้
+type ConflictError struct {
+	Field string
+	Value string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q already exists", e.Field, e.Value)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// StoreError คือ error ของฐานข้อมูลที่ห่อไว้พร้อมชื่อการทำงานและเวลาที่ใช้
+// ข้อความของ error นี้ใช้สำหรับ log เท่านั้น และจะไม่ถูกส่งให้ client
+type StoreError struct {
+	Op       string
+	Duration time.Duration
+	Err      error
+}
+
+func (e *StoreError) Error() string {
+	return fmt.Sprintf("store: %s failed after %s: %v", e.Op, e.Duration, e.Err)
+}
+
+func (e *StoreError) Unwrap() error {
+	return e.Err
+}
+
+// wrapStoreErr ห่อ error ใน *err เป็น StoreError ถ้าไม่ใช่ error ที่ผู้เรียกจัดการได้
+// ใช้ผ่าน defer ในเมธอดของ MySQLStore ที่คืน error แบบตั้งชื่อ
+func wrapStoreErr(op string, start time.Time, err *error) {
+	if *err == nil || errors.Is(*err, ErrNotFound) || errors.Is(*err, ErrDuplicate) || errors.Is(*err, ErrFrozen) ||
+		errors.Is(*err, ErrIngredientsUnavailable) {
+		return
+	}
+	*err = &StoreError{Op: op, Duration: time.Since(start), Err: *err}
+}
+
+// duplicateKeyFields คือฟิลด์ที่ unique key แต่ละตัวของ MySQL ป้องกันไม่ให้ซ้ำ
+var duplicateKeyFields = map[string]string{
+	"uq_recipe_name":     "name",
+	"uq_recipe_external": "external_id",
+	"uq_users_username":  "username",
+	"uq_users_email":     "email",
+}
+
+// duplicateField คืนชื่อฟิลด์ของ unique key ใน error 1062 ของ MySQL หรือ fallback ถ้าไม่รู้จัก key
+func duplicateField(err error, fallback string) string {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		for key, field := range duplicateKeyFields {
+			if strings.Contains(mysqlErr.Message, "'"+key+"'") || strings.Contains(mysqlErr.Message, "."+key+"'") {
+				return field
+			}
+		}
+	}
+	return fallback
+}
+
+// recipeConflict สร้าง ConflictError ของ Recipe จากฟิลด์ที่ซ้ำ
+func recipeConflict(recipe Recipe, field string) error {
+	if field == "external_id" {
+		return &ConflictError{Field: field, Value: recipe.ExternalSource + ":" + recipe.ExternalID}
+	}
+	return &ConflictError{Field: "name", Value: recipe.Name}
+}
+
+// respondStoreErr ตอบ error จาก store ด้วยสถานะและรายละเอียดที่ได้จากชนิดของ error
+// error อื่นที่ไม่รู้จักจะได้ 500 โดยไม่ส่งข้อความของ error ให้ client
+func respondStoreErr(c *gin.Context, err error) {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	switch {
+	case errors.As(err, &notFound):
+		respondErr(c, http.StatusNotFound, codeNotFound, notFound.Error())
+	case errors.As(err, &conflict):
+		// ไม่ส่งค่าที่ซ้ำกลับไป เพราะบางฟิลด์เช่น external_id ผู้เรียกทั่วไปไม่มีสิทธิ์เห็น
+		RespondError(c, http.StatusConflict, []AppError{{Code: codeConflict, Field: conflict.Field, Message: conflict.Field + " already exists"}})
+	case errors.Is(err, ErrFrozen):
+		respondErr(c, http.StatusLocked, codeLocked, err.Error())
+	case errors.Is(err, ErrIngredientsUnavailable):
+		respondErr(c, http.StatusServiceUnavailable, codeUnavailable, err.Error())
+	case errors.Is(err, ErrNotFound):
+		respondErr(c, http.StatusNotFound, codeNotFound, ErrNotFound.Error())
+	case errors.Is(err, ErrDuplicate):
+		respondErr(c, http.StatusConflict, codeConflict, ErrDuplicate.Error())
+	default:
+		respondErr(c, http.StatusInternalServerError, codeInternalError, err.Error())
+	}
+}
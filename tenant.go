@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTenantID คือ tenant ที่ใช้เมื่อ request ไม่ได้ระบุ tenant
+const defaultTenantID = "default"
+
+// tenantIDKey คือ key ที่ใช้เก็บ tenant id ใน Gin context
+const tenantIDKey = "tenant_id"
+
+// TenantMiddleware ดึง claims และ tenant_id จาก JWT ใน header Authorization แล้วเก็บไว้ใน Gin context
+// request ที่ไม่มี token จะใช้ tenant เริ่มต้น ส่วน token ที่ไม่ถูกต้องจะได้รับ 401
+// Bearer ที่เป็น API key ที่ถูกต้องจะผ่านไปโดยไม่มี claims เพื่อให้ RequireAPIKey ตรวจสอบต่อ
+func TenantMiddleware(secret []byte, keys APIKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := defaultTenantID
+
+		if token := bearerToken(c); token != "" && !keys.valid(token) {
+			claims, err := parseToken(secret, token)
+			if err != nil {
+				respondErr(c, http.StatusUnauthorized, codeUnauthorized, "invalid token")
+				c.Abort()
+				return
+			}
+			c.Set(claimsKey, claims)
+			if claims.TenantID != "" {
+				tenant = claims.TenantID
+			}
+		}
+
+		c.Set(tenantIDKey, tenant)
+		c.Next()
+	}
+}
+
+// bearerToken คืนค่า token จาก header Authorization: Bearer หรือสตริงว่างถ้าไม่มี
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(header, "Bearer ")
+}
+
+// tenantID คืนค่า tenant ของ request ปัจจุบัน
+func tenantID(c *gin.Context) string {
+	if tenant := c.GetString(tenantIDKey); tenant != "" {
+		return tenant
+	}
+	return defaultTenantID
+}
+
+// tenantScopedStore เป็น recipeStore ที่บังคับให้ทุกการเรียกใช้ tenant เดียวกัน
+type tenantScopedStore struct {
+	inner    recipeStore
+	tenantID string
+}
+
+// NewTenantScopedStore สร้าง recipeStore ที่ใส่ tenantID ให้ทุกการเรียกโดยไม่สนใจ tenant ที่ส่งเข้ามา
+func NewTenantScopedStore(inner recipeStore, tenantID string) recipeStore {
+	return &tenantScopedStore{inner: inner, tenantID: tenantID}
+}
+
+// Add เพิ่ม Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) Add(ctx context.Context, _ string, recipe Recipe) (int64, error) {
+	return s.inner.Add(ctx, s.tenantID, recipe)
+}
+
+// Get ดึงข้อมูล Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) Get(ctx context.Context, _ string, id int64) (Recipe, error) {
+	return s.inner.Get(ctx, s.tenantID, id)
+}
+
+// GetMany ดึงข้อมูล Recipe หลายรายการภายใต้ tenant ของ store
+func (s *tenantScopedStore) GetMany(ctx context.Context, _ string, names []string) (map[string]Recipe, error) {
+	return s.inner.GetMany(ctx, s.tenantID, names)
+}
+
+// List ดึงรายการ Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) List(ctx context.Context, _ string, filter RecipeFilter, opts ListOptions) ([]Recipe, int, error) {
+	return s.inner.List(ctx, s.tenantID, filter, opts)
+}
+
+// Update อัพเดตข้อมูล Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) Update(ctx context.Context, _ string, id int64, recipe Recipe) error {
+	return s.inner.Update(ctx, s.tenantID, id, recipe)
+}
+
+// Remove ลบ Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) Remove(ctx context.Context, _ string, id int64) error {
+	return s.inner.Remove(ctx, s.tenantID, id)
+}
+
+// FindByExternalID ดึงข้อมูล Recipe จาก ID ภายนอกภายใต้ tenant ของ store
+func (s *tenantScopedStore) FindByExternalID(ctx context.Context, _, externalID, externalSource string) (Recipe, error) {
+	return s.inner.FindByExternalID(ctx, s.tenantID, externalID, externalSource)
+}
+
+// ListEquipment ดึงรายการอุปกรณ์ภายใต้ tenant ของ store
+func (s *tenantScopedStore) ListEquipment(ctx context.Context, _ string) ([]EquipmentCount, error) {
+	return s.inner.ListEquipment(ctx, s.tenantID)
+}
+
+// SetFrozen ล็อกหรือปลดล็อก Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) SetFrozen(ctx context.Context, _ string, id int64, frozen bool, reason string) error {
+	return s.inner.SetFrozen(ctx, s.tenantID, id, frozen, reason)
+}
+
+// Patch แก้ไข Recipe บางฟิลด์ภายใต้ tenant ของ store
+func (s *tenantScopedStore) Patch(ctx context.Context, _ string, id int64, patch RecipePatch) (Recipe, error) {
+	return s.inner.Patch(ctx, s.tenantID, id, patch)
+}
+
+// AddBatch เพิ่ม Recipe หลายรายการภายใต้ tenant ของ store
+func (s *tenantScopedStore) AddBatch(ctx context.Context, _ string, recipes []Recipe) ([]int64, error) {
+	return s.inner.AddBatch(ctx, s.tenantID, recipes)
+}
+
+// AddIngredient เพิ่มวัตถุดิบให้ Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) AddIngredient(ctx context.Context, _ string, recipeID int64, ingredient Ingredient) (Ingredient, error) {
+	return s.inner.AddIngredient(ctx, s.tenantID, recipeID, ingredient)
+}
+
+// ListIngredients ดึงวัตถุดิบของ Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) ListIngredients(ctx context.Context, _ string, recipeID int64) ([]Ingredient, error) {
+	return s.inner.ListIngredients(ctx, s.tenantID, recipeID)
+}
+
+// RemoveIngredient ลบวัตถุดิบของ Recipe ภายใต้ tenant ของ store
+func (s *tenantScopedStore) RemoveIngredient(ctx context.Context, _ string, recipeID, ingredientID int64) error {
+	return s.inner.RemoveIngredient(ctx, s.tenantID, recipeID, ingredientID)
+}
+
+// Ping ตรวจสอบ store ภายใน ซึ่งไม่ขึ้นกับ tenant
+func (s *tenantScopedStore) Ping(ctx context.Context) error {
+	return s.inner.Ping(ctx)
+}
+
+// PatchBatch แก้ไข Recipe หลายรายการภายใต้ tenant ของ store
+func (s *tenantScopedStore) PatchBatch(ctx context.Context, _ string, items []RecipePatchItem, continueOnError bool) ([]BatchItemResult, error) {
+	return s.inner.PatchBatch(ctx, s.tenantID, items, continueOnError)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware กำหนด deadline ให้ context ของทุก request
+// query ที่ส่งผ่าน store จะถูกยกเลิกเมื่อเกินเวลา และ request จะได้รับ 504
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			respondErr(c, http.StatusGatewayTimeout, codeTimeout, "request timed out")
+		}
+	}
+}
+
+// contextErrorStatus คืนสถานะและรหัส error เมื่อ context ของ request สิ้นสุดแล้ว
+// ใช้แทน 500 เพราะ error จากฐานข้อมูลในกรณีนี้เกิดจาก deadline หรือการยกเลิก ไม่ใช่ความผิดพลาดของเซิร์ฟเวอร์
+func contextErrorStatus(c *gin.Context) (int, string, bool) {
+	switch err := c.Request.Context().Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, codeTimeout, true
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, codeRequestCancelled, true
+	}
+	return 0, "", false
+}
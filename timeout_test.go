@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeoutDefault(t *testing.T) {
+	clearConfigEnv(t)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RequestTimeout != 5*time.Second {
+		t.Errorf("RequestTimeout = %s, want 5s", cfg.RequestTimeout)
+	}
+}
+
+func TestHungStoreReturnsGatewayTimeout(t *testing.T) {
+	store := blockingStore{recipeStore: NewMemoryStore(), started: make(chan struct{}, 1)}
+	server := newTestServerWithStore(t, store, func(cfg *Config) {
+		cfg.RequestTimeout = 50 * time.Millisecond
+	})
+
+	start := time.Now()
+	w := server.do(http.MethodGet, "/recipes", "")
+	expectStatus(t, w, http.StatusGatewayTimeout)
+	if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeTimeout {
+		t.Errorf("errors = %+v, want one timeout", envelope.Errors)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request took %s with a 50ms timeout", elapsed)
+	}
+}
+
+func TestClientCancellationReachesStore(t *testing.T) {
+	store := blockingStore{recipeStore: NewMemoryStore(), started: make(chan struct{})}
+	server := newTestServerWithStore(t, store, nil)
+
+	// client ตัดการเชื่อมต่อระหว่างที่ store ยังทำงานอยู่
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/recipes", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		server.router.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	<-store.started
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler still running after the request context was cancelled")
+	}
+
+	// store คืน context.Canceled จึงได้ 499 ไม่ใช่ 500
+	expectStatus(t, w, statusClientClosedRequest)
+	if envelope := decodeEnvelope(t, w, nil); len(envelope.Errors) != 1 || envelope.Errors[0].Code != codeRequestCancelled {
+		t.Errorf("errors = %+v, want one request_cancelled", envelope.Errors)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestUpdateBumpsUpdatedAtOnly(t *testing.T) {
+	server := newTestServer(t, nil)
+	created := server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	if !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Errorf("new recipe: updated_at %s != created_at %s", created.UpdatedAt, created.CreatedAt)
+	}
+
+	updates := []struct {
+		method, body string
+	}{
+		{http.MethodPut, `{"name":"Pad Thai","description":"changed","equipment":[]}`},
+		{http.MethodPatch, `{"description":"patched"}`},
+	}
+	previous := created
+	for _, update := range updates {
+		time.Sleep(2 * time.Millisecond)
+		w := server.do(update.method, "/recipes/1", update.body, writeHeaders...)
+		expectStatus(t, w, http.StatusOK)
+		var updated Recipe
+		decodeEnvelope(t, w, &updated)
+
+		if !updated.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("%s changed created_at from %s to %s", update.method, created.CreatedAt, updated.CreatedAt)
+		}
+		if !updated.UpdatedAt.After(previous.UpdatedAt) {
+			t.Errorf("%s left updated_at at %s, want after %s", update.method, updated.UpdatedAt, previous.UpdatedAt)
+		}
+
+		// GET คืนเวลาเดียวกับที่การอัปเดตตอบกลับ
+		w = server.do(http.MethodGet, "/recipes/1", "")
+		var stored Recipe
+		decodeEnvelope(t, w, &stored)
+		if !stored.UpdatedAt.Equal(updated.UpdatedAt) || !stored.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("GET after %s = %s / %s, want %s / %s", update.method, stored.CreatedAt, stored.UpdatedAt, created.CreatedAt, updated.UpdatedAt)
+		}
+		previous = updated
+	}
+}
+
+func TestClientTimestampsAreIgnored(t *testing.T) {
+	server := newTestServer(t, nil)
+	const forged = "2000-01-01T00:00:00Z"
+	before := time.Now().Add(-time.Minute)
+
+	created := server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[],"created_at":"`+forged+`","updated_at":"`+forged+`"}`)
+	if created.CreatedAt.Before(before) || created.UpdatedAt.Before(before) {
+		t.Errorf("POST kept client timestamps: %s / %s", created.CreatedAt, created.UpdatedAt)
+	}
+
+	w := server.do(http.MethodPut, "/recipes/1", `{"name":"Pad Thai","description":"d","equipment":[],"created_at":"`+forged+`","updated_at":"`+forged+`"}`, writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	var updated Recipe
+	decodeEnvelope(t, w, &updated)
+	if !updated.CreatedAt.Equal(created.CreatedAt) || updated.UpdatedAt.Before(before) {
+		t.Errorf("PUT kept client timestamps: %s / %s", updated.CreatedAt, updated.UpdatedAt)
+	}
+}
+
+func TestTimestampsAreRFC3339(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+
+	w := server.do(http.MethodGet, "/recipes/1", "")
+	var raw struct {
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	decodeEnvelope(t, w, &raw)
+	for name, value := range map[string]string{"created_at": raw.CreatedAt, "updated_at": raw.UpdatedAt} {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t.Errorf("%s = %q is not RFC3339: %v", name, value, err)
+		}
+	}
+}
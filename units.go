@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wiratkhamphan/go-rest-demo/units"
+)
+
+// UnitsResponse คือผลลัพธ์ของ GET /units
+type UnitsResponse struct {
+	Units     []units.Unit       `json:"units"`
+	Densities map[string]float64 `json:"densities"`
+}
+
+// UnitsHandler คือ handler สำหรับข้อมูลหน่วยของวัตถุดิบ
+type UnitsHandler struct {
+	table *units.Table
+}
+
+// NewUnitsHandler สร้าง UnitsHandler ใหม่
+func NewUnitsHandler(table *units.Table) *UnitsHandler {
+	return &UnitsHandler{table: table}
+}
+
+// ListUnits คือ handler สำหรับดูหน่วยและความหนาแน่นของวัตถุดิบที่รองรับ
+func (h *UnitsHandler) ListUnits(c *gin.Context) {
+	RespondSuccess(c, http.StatusOK, UnitsResponse{Units: h.table.Units(), Densities: h.table.Densities()})
+}
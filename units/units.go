@@ -0,0 +1,249 @@
+// Package units แปลงหน่วยของปริมาณวัตถุดิบ (มวล ปริมาตร และจำนวน)
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Dimension คือมิติของหน่วย หน่วยในมิติเดียวกันเท่านั้นที่แปลงกันได้โดยตรง
+type Dimension string
+
+// มิติที่รองรับ
+const (
+	Mass   Dimension = "mass"
+	Volume Dimension = "volume"
+	Count  Dimension = "count"
+)
+
+// ErrUnknownUnit คือ error เมื่อไม่รู้จักหน่วย
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// ErrIncompatible คือ error เมื่อแปลงข้ามมิติโดยไม่มีความหนาแน่น
+var ErrIncompatible = errors.New("units are not compatible")
+
+// Unit คือหน่วยหนึ่งหน่วย Factor คือจำนวนหน่วยฐานของมิติต่อหนึ่งหน่วยนี้
+// หน่วยฐานคือกรัมสำหรับมวล มิลลิลิตรสำหรับปริมาตร และชิ้นสำหรับจำนวน
+type Unit struct {
+	Symbol    string    `json:"symbol"`
+	Dimension Dimension `json:"dimension"`
+	Factor    float64   `json:"factor"`
+	Aliases   []string  `json:"aliases,omitempty"`
+
+	// System คือระบบหน่วย เช่น metric หรือ us โดย Nicest จะเลือกหน่วยในระบบเดียวกันเท่านั้น
+	System string `json:"system,omitempty"`
+
+	// Display บอกว่าหน่วยนี้ใช้เลือกแสดงผลใน Nicest ได้หรือไม่
+	Display bool `json:"display"`
+}
+
+// defaultUnits คือตารางหน่วยเริ่มต้น
+var defaultUnits = []Unit{
+	{Symbol: "mg", Dimension: Mass, Factor: 0.001, System: "metric", Aliases: []string{"milligram", "milligrams"}, Display: true},
+	{Symbol: "g", Dimension: Mass, Factor: 1, System: "metric", Aliases: []string{"gram", "grams"}, Display: true},
+	{Symbol: "kg", Dimension: Mass, Factor: 1000, System: "metric", Aliases: []string{"kilogram", "kilograms"}, Display: true},
+	{Symbol: "oz", Dimension: Mass, Factor: 28.349523125, System: "us", Aliases: []string{"ounce", "ounces"}, Display: true},
+	{Symbol: "lb", Dimension: Mass, Factor: 453.59237, System: "us", Aliases: []string{"pound", "pounds", "lbs"}, Display: true},
+	{Symbol: "ml", Dimension: Volume, Factor: 1, System: "metric", Aliases: []string{"milliliter", "milliliters", "millilitre", "millilitres"}, Display: true},
+	{Symbol: "l", Dimension: Volume, Factor: 1000, System: "metric", Aliases: []string{"liter", "liters", "litre", "litres"}, Display: true},
+	{Symbol: "tsp", Dimension: Volume, Factor: 5, System: "us", Aliases: []string{"teaspoon", "teaspoons"}, Display: true},
+	{Symbol: "tbsp", Dimension: Volume, Factor: 15, System: "us", Aliases: []string{"tablespoon", "tablespoons"}, Display: true},
+	{Symbol: "cup", Dimension: Volume, Factor: 240, System: "us", Aliases: []string{"cups"}, Display: true},
+	{Symbol: "pc", Dimension: Count, Factor: 1, Aliases: []string{"piece", "pieces", "pcs"}, Display: true},
+	{Symbol: "dozen", Dimension: Count, Factor: 12},
+}
+
+// Table คือตารางหน่วยที่ใช้แปลงและค้นหาหน่วย
+type Table struct {
+	units   []Unit
+	symbols map[string]Unit
+
+	// densities คือความหนาแน่นของวัตถุดิบเป็นกรัมต่อมิลลิลิตร ตามชื่อวัตถุดิบตัวพิมพ์เล็ก
+	densities map[string]float64
+}
+
+// Config คือรูปแบบของไฟล์ JSON ที่ใช้เพิ่มหน่วยและความหนาแน่นของวัตถุดิบโดยไม่ต้องคอมไพล์ใหม่
+type Config struct {
+	Units     []Unit             `json:"units"`
+	Densities map[string]float64 `json:"densities"`
+}
+
+// NewTable สร้างตารางจากหน่วยเริ่มต้นและหน่วยเพิ่มเติม
+// หน่วยเพิ่มเติมที่มีสัญลักษณ์ซ้ำกับหน่วยเดิมจะแทนที่หน่วยเดิม
+func NewTable(extra ...Unit) *Table {
+	t := &Table{symbols: make(map[string]Unit), densities: make(map[string]float64)}
+	for _, unit := range append(append([]Unit{}, defaultUnits...), extra...) {
+		t.add(unit)
+	}
+	return t
+}
+
+// LoadTable สร้างตารางโดยอ่าน Config จากไฟล์ JSON ที่ path ถ้า path ว่างจะใช้หน่วยเริ่มต้นเท่านั้น
+func LoadTable(path string) (*Table, error) {
+	if path == "" {
+		return NewTable(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	for _, unit := range config.Units {
+		if unit.Symbol == "" || unit.Factor <= 0 {
+			return nil, errors.New("units: each unit needs a symbol and a positive factor")
+		}
+	}
+
+	t := NewTable(config.Units...)
+	for ingredient, density := range config.Densities {
+		if density <= 0 {
+			return nil, errors.New("units: density of " + ingredient + " must be positive")
+		}
+		t.densities[strings.ToLower(ingredient)] = density
+	}
+	return t, nil
+}
+
+// add เพิ่มหน่วยลงในตาราง
+func (t *Table) add(unit Unit) {
+	unit.Symbol = strings.ToLower(unit.Symbol)
+	if old, ok := t.symbols[unit.Symbol]; ok {
+		for i := range t.units {
+			if t.units[i].Symbol == old.Symbol {
+				t.units = append(t.units[:i], t.units[i+1:]...)
+				break
+			}
+		}
+	}
+
+	t.units = append(t.units, unit)
+	t.symbols[unit.Symbol] = unit
+	for _, alias := range unit.Aliases {
+		t.symbols[strings.ToLower(alias)] = unit
+	}
+}
+
+// Units คืนรายการหน่วยทั้งหมด เรียงตามมิติและขนาด
+func (t *Table) Units() []Unit {
+	units := append([]Unit{}, t.units...)
+	sort.SliceStable(units, func(i, j int) bool {
+		if units[i].Dimension != units[j].Dimension {
+			return units[i].Dimension < units[j].Dimension
+		}
+		return units[i].Factor < units[j].Factor
+	})
+	return units
+}
+
+// Lookup ค้นหาหน่วยจากสัญลักษณ์หรือชื่อเรียกอื่น โดยไม่สนใจตัวพิมพ์และช่องว่าง
+func (t *Table) Lookup(symbol string) (Unit, bool) {
+	unit, ok := t.symbols[strings.ToLower(strings.TrimSpace(symbol))]
+	return unit, ok
+}
+
+// Quantity คือปริมาณพร้อมหน่วย Unknown เป็น true เมื่อตารางไม่รู้จักหน่วย
+// ซึ่งปริมาณจะถูกส่งต่อไปโดยไม่เปลี่ยนแปลง
+type Quantity struct {
+	Amount  float64 `json:"amount"`
+	Unit    string  `json:"unit"`
+	Unknown bool    `json:"unknown,omitempty"`
+}
+
+// Normalize แปลงชื่อหน่วยให้เป็นสัญลักษณ์มาตรฐาน เช่น "Grams" เป็น "g"
+// หน่วยที่ไม่รู้จักจะคืนค่าเดิมและตั้ง Unknown
+func (t *Table) Normalize(q Quantity) Quantity {
+	unit, ok := t.Lookup(q.Unit)
+	if !ok {
+		q.Unknown = true
+		return q
+	}
+	q.Unit = unit.Symbol
+	q.Unknown = false
+	return q
+}
+
+// Convert แปลงปริมาณจากหน่วย from เป็นหน่วย to
+// density คือความหนาแน่นเป็นกรัมต่อมิลลิลิตร ใช้เฉพาะการแปลงระหว่างมวลกับปริมาตร และต้องมากกว่า 0
+func (t *Table) Convert(amount float64, from, to string, density float64) (float64, error) {
+	fromUnit, ok := t.Lookup(from)
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+	toUnit, ok := t.Lookup(to)
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+
+	base := amount * fromUnit.Factor
+	if fromUnit.Dimension != toUnit.Dimension {
+		switch {
+		case density <= 0:
+			return 0, ErrIncompatible
+		case fromUnit.Dimension == Mass && toUnit.Dimension == Volume:
+			base /= density
+		case fromUnit.Dimension == Volume && toUnit.Dimension == Mass:
+			base *= density
+		default:
+			return 0, ErrIncompatible
+		}
+	}
+	return base / toUnit.Factor, nil
+}
+
+// Nicest แปลงปริมาณเป็นหน่วยที่อ่านง่ายที่สุดในมิติและระบบเดียวกัน
+// คือหน่วยแสดงผลที่ใหญ่ที่สุดที่ทำให้ปริมาณไม่น้อยกว่า 1 เช่น 1000 g เป็น 1 kg, 3 tsp เป็น 1 tbsp
+// และ 0.0007 kg เป็น 700 mg หน่วยที่ไม่รู้จักจะคืนค่าเดิมและตั้ง Unknown
+func (t *Table) Nicest(q Quantity) Quantity {
+	unit, ok := t.Lookup(q.Unit)
+	if !ok {
+		q.Unknown = true
+		return q
+	}
+
+	base := math.Abs(q.Amount * unit.Factor)
+	best, smallest := unit, unit
+	found := false
+	for _, candidate := range t.units {
+		if candidate.Dimension != unit.Dimension || candidate.System != unit.System || !candidate.Display {
+			continue
+		}
+		if candidate.Factor < smallest.Factor {
+			smallest = candidate
+		}
+		if base/candidate.Factor >= 1 && (!found || candidate.Factor > best.Factor) {
+			best, found = candidate, true
+		}
+	}
+	if !found {
+		best = smallest
+	}
+
+	return Quantity{Amount: round(q.Amount * unit.Factor / best.Factor), Unit: best.Symbol}
+}
+
+// Densities คืนความหนาแน่นของวัตถุดิบทั้งหมดที่ตั้งค่าไว้
+func (t *Table) Densities() map[string]float64 {
+	densities := make(map[string]float64, len(t.densities))
+	for ingredient, density := range t.densities {
+		densities[ingredient] = density
+	}
+	return densities
+}
+
+// ConvertIngredient แปลงปริมาณของวัตถุดิบ โดยใช้ความหนาแน่นของวัตถุดิบนั้นถ้าต้องแปลงข้ามมิติ
+func (t *Table) ConvertIngredient(amount float64, from, to, ingredient string) (float64, error) {
+	return t.Convert(amount, from, to, t.densities[strings.ToLower(strings.TrimSpace(ingredient))])
+}
+
+// round ปัดค่าเป็นทศนิยมสามตำแหน่งเพื่อไม่ให้แสดงเศษจากการคำนวณ floating point
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,159 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig เขียนไฟล์ค่าตั้งของหน่วยลงในไดเรกทอรีชั่วคราวและคืน path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "units.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConvert(t *testing.T) {
+	table := NewTable()
+	cases := []struct {
+		amount   float64
+		from, to string
+		density  float64
+		want     float64
+	}{
+		{1000, "g", "kg", 0, 1},
+		{3, "tsp", "tbsp", 0, 1},
+		{1, "Cup", "ml", 0, 240},
+		{2, "dozen", "pcs", 0, 24},
+		{1, "lb", "g", 0, 453.59237},
+		{100, "ml", "g", 0.5, 50},
+		{100, "g", "ml", 0.5, 200},
+	}
+	for _, tc := range cases {
+		got, err := table.Convert(tc.amount, tc.from, tc.to, tc.density)
+		if err != nil {
+			t.Errorf("Convert(%v %s to %s): %v", tc.amount, tc.from, tc.to, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Convert(%v %s to %s) = %v, want %v", tc.amount, tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	table := NewTable()
+	cases := []struct {
+		from, to string
+		density  float64
+		want     error
+	}{
+		// มวลกับปริมาตรแปลงกันไม่ได้ถ้าไม่มีความหนาแน่น
+		{"g", "ml", 0, ErrIncompatible},
+		{"pc", "g", 1, ErrIncompatible},
+		{"bucket", "g", 0, ErrUnknownUnit},
+		{"g", "bucket", 0, ErrUnknownUnit},
+	}
+	for _, tc := range cases {
+		if _, err := table.Convert(1, tc.from, tc.to, tc.density); !errors.Is(err, tc.want) {
+			t.Errorf("Convert(%s to %s) = %v, want %v", tc.from, tc.to, err, tc.want)
+		}
+	}
+}
+
+func TestNicest(t *testing.T) {
+	table := NewTable()
+	cases := []struct {
+		in, want Quantity
+	}{
+		{Quantity{Amount: 1000, Unit: "g"}, Quantity{Amount: 1, Unit: "kg"}},
+		{Quantity{Amount: 0.0007, Unit: "kg"}, Quantity{Amount: 700, Unit: "mg"}},
+		{Quantity{Amount: 3, Unit: "tsp"}, Quantity{Amount: 1, Unit: "tbsp"}},
+		{Quantity{Amount: 1500, Unit: "ml"}, Quantity{Amount: 1.5, Unit: "l"}},
+		// ไม่ข้ามระบบหน่วย: ออนซ์ไม่ถูกแปลงเป็นกรัม
+		{Quantity{Amount: 32, Unit: "oz"}, Quantity{Amount: 2, Unit: "lb"}},
+		// dozen ไม่ใช่หน่วยแสดงผล
+		{Quantity{Amount: 2, Unit: "dozen"}, Quantity{Amount: 24, Unit: "pc"}},
+		{Quantity{Amount: 0, Unit: "g"}, Quantity{Amount: 0, Unit: "mg"}},
+	}
+	for _, tc := range cases {
+		if got := table.Nicest(tc.in); got != tc.want {
+			t.Errorf("Nicest(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestUnknownUnitsPassThrough(t *testing.T) {
+	table := NewTable()
+	in := Quantity{Amount: 2, Unit: "handful"}
+	want := Quantity{Amount: 2, Unit: "handful", Unknown: true}
+	if got := table.Normalize(in); got != want {
+		t.Errorf("Normalize(%v) = %v, want %v", in, got, want)
+	}
+	if got := table.Nicest(in); got != want {
+		t.Errorf("Nicest(%v) = %v, want %v", in, got, want)
+	}
+	if got := table.Normalize(Quantity{Amount: 1, Unit: " Grams "}); got != (Quantity{Amount: 1, Unit: "g"}) {
+		t.Errorf("Normalize(Grams) = %v", got)
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	path := writeConfig(t, `{
+		"units": [
+			{"symbol": "pinch", "dimension": "mass", "factor": 0.36, "display": false},
+			{"symbol": "cup", "dimension": "volume", "factor": 250, "system": "metric", "display": true}
+		],
+		"densities": {"Honey": 1.42}
+	}`)
+	table, err := LoadTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := table.Convert(10, "pinch", "g", 0); err != nil || math.Abs(got-3.6) > 1e-9 {
+		t.Errorf("Convert(10 pinch to g) = %v, %v", got, err)
+	}
+	// หน่วยที่มีสัญลักษณ์ซ้ำแทนที่หน่วยเดิม
+	if got, _ := table.Convert(1, "cup", "ml", 0); got != 250 {
+		t.Errorf("1 cup = %v ml, want the configured 250", got)
+	}
+	cups := 0
+	for _, unit := range table.Units() {
+		if unit.Symbol == "cup" {
+			cups++
+		}
+	}
+	if cups != 1 {
+		t.Errorf("Units() lists cup %d times", cups)
+	}
+	// ความหนาแน่นค้นหาโดยไม่สนตัวพิมพ์
+	if got, err := table.ConvertIngredient(100, "ml", "g", " honey"); err != nil || math.Abs(got-142) > 1e-9 {
+		t.Errorf("ConvertIngredient(100 ml honey to g) = %v, %v", got, err)
+	}
+}
+
+func TestLoadTableErrors(t *testing.T) {
+	cases := map[string]string{
+		"not json":         `{"units":`,
+		"missing symbol":   `{"units":[{"dimension":"mass","factor":1}]}`,
+		"zero factor":      `{"units":[{"symbol":"x","dimension":"mass","factor":0}]}`,
+		"negative density": `{"densities":{"honey":-1}}`,
+	}
+	for name, content := range cases {
+		if _, err := LoadTable(writeConfig(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := LoadTable(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file: expected an error")
+	}
+	if table, err := LoadTable(""); err != nil || len(table.Units()) != len(defaultUnits) {
+		t.Errorf("LoadTable(\"\") = %d units, %v; want the defaults", len(table.Units()), err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListUnits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	if err := os.WriteFile(path, []byte(`{"units":[{"symbol":"pinch","dimension":"mass","factor":0.36}],"densities":{"honey":1.42}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, func(cfg *Config) {
+		cfg.UnitsFile = path
+	})
+
+	w := server.do(http.MethodGet, "/units", "")
+	expectStatus(t, w, http.StatusOK)
+	var response UnitsResponse
+	decodeEnvelope(t, w, &response)
+
+	symbols := make(map[string]bool)
+	for _, unit := range response.Units {
+		symbols[unit.Symbol] = true
+	}
+	for _, symbol := range []string{"g", "kg", "ml", "tbsp", "pc", "pinch"} {
+		if !symbols[symbol] {
+			t.Errorf("GET /units does not list %q", symbol)
+		}
+	}
+	if response.Densities["honey"] != 1.42 {
+		t.Errorf("densities = %v", response.Densities)
+	}
+
+	// หน่วยที่ตั้งค่าเพิ่มใช้กับวัตถุดิบได้ทันที
+	server.createRecipe(t, `{"name":"Pad Thai","description":"d","equipment":[]}`)
+	w = server.do(http.MethodPost, "/recipes/1/ingredients", `{"name":"salt","quantity":1,"unit":"pinch"}`, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNameRunes คือความยาวสูงสุดของชื่อ Recipe ตรงกับคอลัมน์ name VARCHAR(255)
+const maxNameRunes = 255
+
+// ความยาวสูงสุดของฟิลด์ข้อความที่ไม่บังคับ ตรงกับขนาดของคอลัมน์ในตาราง recipe
+// ค่าที่ยาวเกินต้องได้ 422 ที่นี่ เพราะ MySQL จะปฏิเสธด้วย error 1406 ซึ่งกลายเป็น 500
+const (
+	maxSourceURLRunes      = 2048
+	maxSourceNameRunes     = 255
+	maxExternalIDRunes     = 100
+	maxExternalSourceRunes = 50
+
+	// maxEquipmentNameRunes ตรงกับ equipment_name VARCHAR(255) ซึ่งเป็นส่วนหนึ่งของ primary key ของ recipe_equipment
+	maxEquipmentNameRunes = 255
+)
+
+// unknownFieldError คือ error เมื่อ request body มีฟิลด์ที่ Recipe ไม่รู้จัก
+type unknownFieldError struct {
+	Field string
+}
+
+func (e *unknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// decodeStrict แปลง JSON เป็น v โดยไม่รับฟิลด์ที่ไม่รู้จัก เพื่อไม่ให้ชื่อฟิลด์ที่พิมพ์ผิดถูกทิ้งไปเงียบ ๆ
+// ฟิลด์ที่ไม่รู้จักคืนเป็น *unknownFieldError ส่วน JSON ที่ไม่ถูกต้องคืน error ของ decoder ตามเดิม
+func decodeStrict(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field ") {
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &unknownFieldError{Field: field}
+	}
+	return err
+}
+
+// decodeStrictBytes คือ decodeStrict สำหรับ JSON ที่อ่านมาแล้ว
+func decodeStrictBytes(data []byte, v interface{}) error {
+	return decodeStrict(bytes.NewReader(data), v)
+}
+
+// validateName ตรวจสอบชื่อ Recipe ที่ตัดช่องว่างหัวท้ายแล้ว
+func validateName(name string) []AppError {
+	switch {
+	case name == "":
+		return []AppError{{Code: codeValidationFailed, Field: "name", Message: "name is required"}}
+	case utf8.RuneCountInString(name) > maxNameRunes:
+		return []AppError{{Code: codeValidationFailed, Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxNameRunes)}}
+	}
+	return nil
+}
+
+// validateLength ตรวจสอบว่าค่าของฟิลด์ยาวไม่เกิน max ตัวอักษร
+func validateLength(field, value string, max int) []AppError {
+	if utf8.RuneCountInString(value) > max {
+		return []AppError{{Code: codeValidationFailed, Field: field, Message: fmt.Sprintf("%s must be at most %d characters", field, max)}}
+	}
+	return nil
+}
+
+// validateSource ตรวจสอบความยาวของ source_url และ source_name
+func validateSource(sourceURL, sourceName string) []AppError {
+	details := validateLength("source_url", sourceURL, maxSourceURLRunes)
+	return append(details, validateLength("source_name", sourceName, maxSourceNameRunes)...)
+}
+
+// validateExternalID ตรวจสอบ external_id และ external_source ซึ่งต้องมีทั้งคู่หรือไม่มีเลย
+// เพราะ unique key uq_recipe_external ไม่ตรวจแถวที่คอลัมน์ใดเป็น NULL จึงกันการ import ซ้ำไม่ได้
+func validateExternalID(externalID, externalSource string) []AppError {
+	if (externalID == "") != (externalSource == "") {
+		field := "external_source"
+		if externalID == "" {
+			field = "external_id"
+		}
+		return []AppError{{Code: codeValidationFailed, Field: field, Message: "external_id and external_source must be given together"}}
+	}
+	details := validateLength("external_id", externalID, maxExternalIDRunes)
+	return append(details, validateLength("external_source", externalSource, maxExternalSourceRunes)...)
+}
+
+// validateEquipment ตรวจสอบความยาวของชื่ออุปกรณ์แต่ละรายการหลังตัดช่องว่าง
+// ชื่อว่างและชื่อที่ซ้ำกันไม่ใช่ error เพราะ normalizeEquipment ลบออกให้ก่อนบันทึก
+func validateEquipment(equipment []string) []AppError {
+	var details []AppError
+	for i, name := range equipment {
+		if utf8.RuneCountInString(strings.TrimSpace(name)) > maxEquipmentNameRunes {
+			details = append(details, AppError{Code: codeValidationFailed, Field: fmt.Sprintf("equipment[%d]", i), Message: fmt.Sprintf("equipment names must be at most %d characters", maxEquipmentNameRunes)})
+		}
+	}
+	return details
+}
+
+// validateRecipe ตัดช่องว่างหัวท้ายของชื่อและจัดรายการอุปกรณ์ด้วย normalizeEquipment แล้วตรวจสอบ Recipe ก่อนบันทึก
+// คืนรายละเอียดของทุกฟิลด์ที่ไม่ผ่าน ความยาวนับเป็นตัวอักษร (rune) ไม่ใช่ byte
+func (h *RecipesHandler) validateRecipe(recipe *Recipe) []AppError {
+	recipe.Name = strings.TrimSpace(recipe.Name)
+	equipmentDetails := validateEquipment(recipe.Equipment)
+	recipe.Equipment = normalizeEquipment(recipe.Equipment)
+	details := validateName(recipe.Name)
+	if !h.limits.descriptionFits(recipe.Description) {
+		details = append(details, AppError{Code: codeValidationFailed, Field: "description", Message: h.limits.descriptionTooLong()})
+	}
+	details = append(details, validateSource(recipe.SourceURL, recipe.SourceName)...)
+	details = append(details, validateExternalID(recipe.ExternalID, recipe.ExternalSource)...)
+	details = append(details, equipmentDetails...)
+	return append(details, h.metadata.validate(recipe.Metadata)...)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recipeJSON สร้าง body ของ POST /recipes จากชื่อและคำอธิบาย
+func recipeJSON(t *testing.T, name, description string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"name": name, "description": description, "equipment": []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestValidateName(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{"Pad Thai", true},
+		{"", false},
+		{strings.Repeat("a", maxNameRunes), true},
+		{strings.Repeat("a", maxNameRunes+1), false},
+		// ภาษาไทยใช้ 3 byte ต่อตัวอักษร แต่ความยาวนับเป็นตัวอักษร
+		{strings.Repeat("ก", maxNameRunes), true},
+		{strings.Repeat("ก", maxNameRunes+1), false},
+	}
+	for _, tc := range cases {
+		if details := validateName(tc.name); (len(details) == 0) != tc.valid {
+			t.Errorf("validateName(%d runes, %d bytes) = %+v, want valid %v", len([]rune(tc.name)), len(tc.name), details, tc.valid)
+		}
+	}
+}
+
+func TestCreateRecipeValidation(t *testing.T) {
+	cases := []struct {
+		name, recipeName, description string
+		fields                        []string
+	}{
+		{"empty name", "", "d", []string{"name"}},
+		{"whitespace name", " \t\n ", "d", []string{"name"}},
+		{"name too long", strings.Repeat("a", maxNameRunes+1), "d", []string{"name"}},
+		{"thai name too long", strings.Repeat("ก", maxNameRunes+1), "d", []string{"name"}},
+		{"description too long", "Pad Thai", strings.Repeat("a", defaultMaxDescriptionRunes+1), []string{"description"}},
+		{"thai description too long", "Pad Thai", strings.Repeat("ก", defaultMaxDescriptionRunes+1), []string{"description"}},
+		{"every field", "", strings.Repeat("a", defaultMaxDescriptionRunes+1), []string{"name", "description"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			w := server.do(http.MethodPost, "/recipes", recipeJSON(t, tc.recipeName, tc.description), writeHeaders...)
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+
+			// ทุกฟิลด์ที่ไม่ผ่านถูกรายงานพร้อมข้อความที่อ่านเข้าใจได้
+			envelope := decodeEnvelope(t, w, nil)
+			var fields []string
+			for _, e := range envelope.Errors {
+				if e.Code != codeValidationFailed || e.Message == "" {
+					t.Errorf("error %+v", e)
+				}
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tc.fields)
+			}
+		})
+	}
+}
+
+func TestCreateRecipeAtLimits(t *testing.T) {
+	server := newTestServer(t, nil)
+	name := strings.Repeat("ก", maxNameRunes)
+	description := strings.Repeat("ก", defaultMaxDescriptionRunes)
+	created := server.createRecipe(t, recipeJSON(t, name, description))
+	if created.Name != name {
+		t.Errorf("name has %d runes, want %d", len([]rune(created.Name)), maxNameRunes)
+	}
+}
+
+func TestCreateRecipeTrimsName(t *testing.T) {
+	server := newTestServer(t, nil)
+	created := server.createRecipe(t, recipeJSON(t, "  Pad Thai\t", "d"))
+	if created.Name != "Pad Thai" {
+		t.Errorf("name = %q, want trimmed", created.Name)
+	}
+
+	// ชื่อที่ต่างกันแค่ช่องว่างหัวท้ายถือเป็นชื่อเดียวกัน
+	w := server.do(http.MethodPost, "/recipes", recipeJSON(t, "Pad Thai ", "d"), writeHeaders...)
+	expectStatus(t, w, http.StatusConflict)
+}
+
+func TestRecipeUnknownFieldsRejected(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, recipeJSON(t, "Pad Thai", "d"))
+
+	body := `{"name":"Pad Thai","descripton":"typo","equipment":[]}`
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPost, "/recipes"},
+		{http.MethodPut, "/recipes/1"},
+	} {
+		w := server.do(req.method, req.path, body, writeHeaders...)
+		expectStatus(t, w, http.StatusUnprocessableEntity)
+		envelope := decodeEnvelope(t, w, nil)
+		if len(envelope.Errors) != 1 || envelope.Errors[0].Field != "descripton" {
+			t.Errorf("%s %s errors = %+v, want the unknown field named", req.method, req.path, envelope.Errors)
+		}
+	}
+}
+
+func TestUpdateRecipeValidation(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.createRecipe(t, recipeJSON(t, "Pad Thai", "d"))
+
+	w := server.do(http.MethodPut, "/recipes/1", recipeJSON(t, "   ", "d"), writeHeaders...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+
+	// การอัปเดตที่ไม่ผ่านไม่เปลี่ยนข้อมูลเดิม
+	w = server.do(http.MethodGet, "/recipes/1", "")
+	var recipe Recipe
+	decodeEnvelope(t, w, &recipe)
+	if recipe.Name != "Pad Thai" {
+		t.Errorf("name = %q after a rejected update", recipe.Name)
+	}
+}
+
+// fieldLimitCase คือ body ที่มีฟิลด์เกินขนาดคอลัมน์ และฟิลด์ที่ต้องถูกรายงาน
+type fieldLimitCase struct {
+	name   string
+	extra  map[string]interface{}
+	fields []string
+}
+
+// recipeWith สร้าง body ของ Recipe ที่ถูกต้องแล้วเพิ่มฟิลด์จาก extra
+func recipeWith(t *testing.T, extra map[string]interface{}) string {
+	t.Helper()
+	recipe := map[string]interface{}{"name": "Pad Thai", "description": "d", "equipment": []string{}}
+	for key, value := range extra {
+		recipe[key] = value
+	}
+	body, err := json.Marshal(recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+// errorFields คืนชื่อฟิลด์ของทุก error ใน response
+func errorFields(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	var fields []string
+	for _, e := range decodeEnvelope(t, w, nil).Errors {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestOptionalFieldsLimitedToColumnSize(t *testing.T) {
+	rejected := []fieldLimitCase{
+		{"source_url too long", map[string]interface{}{"source_url": "https://example.com/" + strings.Repeat("a", maxSourceURLRunes)}, []string{"source_url"}},
+		{"source_name too long", map[string]interface{}{"source_name": strings.Repeat("ก", maxSourceNameRunes+1)}, []string{"source_name"}},
+		{"external_id too long", map[string]interface{}{"external_id": strings.Repeat("1", maxExternalIDRunes+1), "external_source": "spoonacular"}, []string{"external_id"}},
+		{"external_source too long", map[string]interface{}{"external_id": "12345", "external_source": strings.Repeat("s", maxExternalSourceRunes+1)}, []string{"external_source"}},
+		{"external_id without source", map[string]interface{}{"external_id": "12345"}, []string{"external_source"}},
+		{"external_source without id", map[string]interface{}{"external_source": "spoonacular"}, []string{"external_id"}},
+		{"equipment name too long", map[string]interface{}{"equipment": []string{"wok", strings.Repeat("ก", maxEquipmentNameRunes+1)}}, []string{"equipment[1]"}},
+	}
+	accepted := []map[string]interface{}{
+		{"source_url": strings.Repeat("a", maxSourceURLRunes), "source_name": strings.Repeat("ก", maxSourceNameRunes)},
+		{"external_id": strings.Repeat("1", maxExternalIDRunes), "external_source": strings.Repeat("s", maxExternalSourceRunes)},
+		// ช่องว่างหัวท้ายไม่นับรวมในความยาว
+		{"equipment": []string{" " + strings.Repeat("ก", maxEquipmentNameRunes) + " "}},
+	}
+
+	for _, tc := range rejected {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			w := server.do(http.MethodPost, "/recipes", recipeWith(t, tc.extra), writeHeaders...)
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+			if fields := errorFields(t, w); strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("POST fields = %v, want %v", fields, tc.fields)
+			}
+
+			// PATCH ตรวจด้วยกฎเดียวกัน
+			server.createRecipe(t, recipeWith(t, nil))
+			patch, err := json.Marshal(tc.extra)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w = server.do(http.MethodPatch, "/recipes/1", string(patch), writeHeaders...)
+			expectStatus(t, w, http.StatusUnprocessableEntity)
+			if fields := errorFields(t, w); strings.Join(fields, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("PATCH fields = %v, want %v", fields, tc.fields)
+			}
+		})
+	}
+	for _, extra := range accepted {
+		newTestServer(t, nil).createRecipe(t, recipeWith(t, extra))
+	}
+}
+
+func TestEquipmentEntriesNormalizedBeforeSaving(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	// ชื่อที่ซ้ำกันเมื่อไม่สนตัวพิมพ์และช่องว่างจะชนกันใน primary key ของ recipe_equipment
+	// จึงต้องถูกรวมก่อนถึง store ทั้งตอนเพิ่ม แก้ไข และเพิ่มทีละหลายรายการ
+	created := server.createRecipe(t, `{"name":"Stir Fry","description":"d","equipment":["wok"," Wok ","WOK","","spatula"]}`)
+	if strings.Join(created.Equipment, ",") != "wok,spatula" {
+		t.Errorf("POST equipment = %q", created.Equipment)
+	}
+
+	w := server.do(http.MethodPut, "/recipes/1", `{"name":"Stir Fry","description":"d","equipment":["Pan","pan "]}`, writeHeaders...)
+	expectStatus(t, w, http.StatusOK)
+	var updated Recipe
+	decodeEnvelope(t, w, &updated)
+	if strings.Join(updated.Equipment, ",") != "Pan" {
+		t.Errorf("PUT equipment = %q", updated.Equipment)
+	}
+
+	w = server.do(http.MethodPost, "/recipes/batch", `[{"name":"Larb","description":"d","equipment":["mortar","Mortar"]}]`, writeHeaders...)
+	expectStatus(t, w, http.StatusCreated)
+	if page := server.listRecipes(t, "/recipes?equipment=mortar"); len(page.Recipes) != 1 || strings.Join(page.Recipes[0].Equipment, ",") != "mortar" {
+		t.Errorf("batch equipment = %+v", page.Recipes)
+	}
+
+	w = server.do(http.MethodPost, "/recipes/batch", `[{"name":"Som Tam","description":"d","equipment":["`+strings.Repeat("a", maxEquipmentNameRunes+1)+`"]}]`, writeHeaders...)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	if fields := errorFields(t, w); strings.Join(fields, ",") != "0.equipment[0]" {
+		t.Errorf("batch fields = %v", fields)
+	}
+}